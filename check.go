@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+var checkCommand = cli.Command{
+	Name:  "check",
+	Usage: "service check commands",
+	Subcommands: []cli.Command{
+		{
+			Name:      "post",
+			Usage:     "submit the status of a service check",
+			ArgsUsage: "<check name>",
+			Action:    postServiceCheck,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "status, s",
+					Value: "ok",
+					Usage: "Status, one of ok, warn, critical or unknown",
+				},
+				cli.StringFlag{
+					Name:  "host",
+					Usage: "Host the check ran on (default this host)",
+				},
+				cli.StringSliceFlag{
+					Name:  "tags, t",
+					Usage: "Tag to add to the check (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "Message describing the status",
+				},
+			},
+		},
+		{
+			Name:      "status",
+			Usage:     "list the current status of service check monitors, per group",
+			ArgsUsage: "[check name]",
+			Action:    serviceCheckStatus,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "csv",
+					Usage: "Format, either csv or md (markdown)",
+				},
+			},
+		},
+	},
+}
+
+var serviceCheckStatuses = map[string]int{
+	"ok":       datadog.ServiceCheckOK,
+	"warn":     datadog.ServiceCheckWarning,
+	"warning":  datadog.ServiceCheckWarning,
+	"critical": datadog.ServiceCheckCritical,
+	"unknown":  datadog.ServiceCheckUnknown,
+}
+
+func postServiceCheck(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("check name required")
+	}
+	status, ok := serviceCheckStatuses[strings.ToLower(c.String("status"))]
+	if !ok {
+		return errors.New("--status must be one of ok, warn, critical or unknown")
+	}
+	host := c.String("host")
+	if host == "" {
+		var err error
+		if host, err = os.Hostname(); err != nil {
+			return errors.New("failed to get hostname, use --host: " + err.Error())
+		}
+	}
+
+	api := getAPI()
+	return api.PostServiceCheck(datadog.ServiceCheck{
+		Check:    c.Args()[0],
+		HostName: host,
+		Status:   status,
+		Message:  c.String("message"),
+		Tags:     c.StringSlice("tags"),
+	})
+}
+
+// serviceCheckStatus lists the groups of every service check monitor, or just
+// those monitoring the given check, with their current status.
+func serviceCheckStatus(c *cli.Context) error {
+	api := getAPI()
+	monitors, err := api.GetMonitors()
+	if err != nil {
+		return err
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Monitor ID", "Monitor", "Group", "Status", "Last triggered", "Last resolved"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, m := range monitors {
+		if m.Type != "service check" {
+			continue
+		}
+		if c.NArg() > 0 && !strings.HasPrefix(m.Query, strconv.Quote(c.Args()[0])+".") {
+			continue
+		}
+
+		monitor, err := api.GetMonitor(m.ID)
+		if err != nil {
+			return err
+		}
+		groups := []string{}
+		if monitor.State != nil {
+			for name := range monitor.State.Groups {
+				groups = append(groups, name)
+			}
+		}
+		sort.Strings(groups)
+
+		if len(groups) == 0 {
+			if err := w.Write([]string{strconv.Itoa(m.ID), m.Name, "", monitor.OverallState, "", ""}); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+			continue
+		}
+		for _, name := range groups {
+			group := monitor.State.Groups[name]
+			if err := w.Write([]string{
+				strconv.Itoa(m.ID),
+				m.Name,
+				name,
+				group.Status,
+				formatUnix(group.LastTriggeredTS),
+				formatUnix(group.LastResolvedTS),
+			}); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+		}
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/datadog/query"
+	"github.com/porty/ddcli/plan"
+	"github.com/urfave/cli"
+)
+
+var dashboardsCommand = cli.Command{
+	Name:  "dashboards",
+	Usage: "timeboard and screenboard commands",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list timeboards and screenboards",
+			Action: listDashboards,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "title, t",
+					Usage: "Only list boards with titles containing this, ignoring case",
+				},
+				cli.StringFlag{
+					Name:  "modified-since, m",
+					Usage: "Only list boards modified since a duration ago, e.g. 30d or 12h, RFC3339 or a Unix timestamp",
+				},
+				cli.BoolFlag{
+					Name:  "read-only",
+					Usage: "Only list read only boards",
+				},
+				cli.StringFlag{
+					Name:  "sort, s",
+					Value: "title",
+					Usage: "Sort by title or modified, most recent first",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "csv",
+					Usage: "Format, either csv or md (markdown)",
+				},
+			},
+		},
+		{
+			Name:      "show",
+			Usage:     "print a board's graphs and their queries as an outline",
+			ArgsUsage: "<id>",
+			Action:    showDashboard,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "type",
+					Usage: "Board type, either timeboard or screenboard (default timeboard, then screenboard if not found)",
+				},
+			},
+		},
+		{
+			Name:      "clone",
+			Usage:     "create a copy of a board, replacing tags in its queries",
+			ArgsUsage: "<id>",
+			Description: "Each --replace tag is replaced in graph and widget queries and template variable\n" +
+				"   defaults, and its value is replaced in the board and graph titles, e.g.\n" +
+				"   --replace service:checkout=service:payments turns \"checkout latency\" into \"payments latency\".\n" +
+				"   Titles are matched case sensitively.",
+			Action: cloneDashboard,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "title, t",
+					Usage: "Title of the new board (default the original title with replacements made)",
+				},
+				cli.StringSliceFlag{
+					Name:  "replace, r",
+					Usage: "Replace a tag, e.g. service:old=service:new (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "type",
+					Usage: "Board type, either timeboard or screenboard (default timeboard, then screenboard if not found)",
+				},
+			},
+		},
+	},
+}
+
+type boardSummary struct {
+	ID       string
+	Title    string
+	Type     string
+	Modified time.Time
+	ReadOnly bool
+	URL      string
+}
+
+func listDashboards(c *cli.Context) error {
+	var since time.Time
+	if s := c.String("modified-since"); s != "" {
+		now := time.Now()
+		if age, err := parseAge(s); err == nil {
+			since = now.Add(-1 * age)
+		} else if since, err = parseTimeOrAgo(s, now); err != nil {
+			return errors.New("invalid --modified-since: " + err.Error())
+		}
+	}
+	sortBy := c.String("sort")
+	if sortBy != "title" && sortBy != "modified" {
+		return errors.New("--sort must be either title or modified")
+	}
+
+	api := getAPI()
+	boards := []boardSummary{}
+	dashes, err := api.GetDashboards()
+	if err != nil {
+		return err
+	}
+	for _, d := range dashes {
+		boards = append(boards, boardSummary{d.ID, d.Title, "timeboard", d.Modified, d.ReadOnly, api.DashboardURL(d.ID)})
+	}
+	screenboards, err := api.GetScreenboards()
+	if err != nil {
+		return err
+	}
+	for _, s := range screenboards {
+		boards = append(boards, boardSummary{strconv.Itoa(s.ID), s.Title, "screenboard", s.Modified, s.ReadOnly, api.ScreenboardURL(s.ID)})
+	}
+
+	if sortBy == "title" {
+		sort.SliceStable(boards, func(i, j int) bool {
+			return strings.ToLower(boards[i].Title) < strings.ToLower(boards[j].Title)
+		})
+	} else {
+		sort.SliceStable(boards, func(i, j int) bool {
+			return boards[i].Modified.After(boards[j].Modified)
+		})
+	}
+
+	title := strings.ToLower(c.String("title"))
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"ID", "Title", "Type", "Modified", "URL"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, b := range boards {
+		if !strings.Contains(strings.ToLower(b.Title), title) ||
+			b.Modified.Before(since) ||
+			(c.Bool("read-only") && !b.ReadOnly) {
+			continue
+		}
+		if err := w.Write([]string{b.ID, b.Title, b.Type, b.Modified.Local().Format("2006-01-02 15:04"), b.URL}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func showDashboard(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("board id required")
+	}
+	id := c.Args()[0]
+	boardType := c.String("type")
+	if boardType != "" && boardType != "timeboard" && boardType != "screenboard" {
+		return errors.New("--type must be either timeboard or screenboard")
+	}
+
+	api := getAPI()
+	if boardType != "screenboard" {
+		dash, err := api.GetDashboard(id)
+		if err == nil {
+			printDashboardOutline(dash, api.DashboardURL(id))
+			return nil
+		}
+		if boardType == "timeboard" {
+			return err
+		}
+	}
+	screenboardID, err := strconv.Atoi(id)
+	if err != nil {
+		return errors.New("invalid screenboard id " + id)
+	}
+	screenboard, err := api.GetScreenboard(screenboardID)
+	if err != nil {
+		return err
+	}
+	printScreenboardOutline(screenboard, api.ScreenboardURL(screenboardID))
+	return nil
+}
+
+func printDashboardOutline(dash *datadog.Dashboard, url string) {
+	fmt.Printf("%s (timeboard %d)\n%s\n", dash.Title, dash.ID, url)
+	if dash.Description != "" {
+		fmt.Println(dash.Description)
+	}
+	if len(dash.TemplateVariables) > 0 {
+		fmt.Println("\nTemplate variables:")
+		for _, v := range dash.TemplateVariables {
+			fmt.Printf("  $%s (prefix %s, default %s)\n", v.Name, v.Prefix, v.Default)
+		}
+	}
+	fmt.Println()
+	for i, g := range dash.Graphs {
+		fmt.Printf("%d. %s [%s]\n", i+1, g.Title, g.Definition.Viz)
+		for _, r := range g.Definition.Requests {
+			fmt.Printf("   - %s\n", r.Q)
+		}
+	}
+}
+
+func printScreenboardOutline(screenboard *datadog.Screenboard, url string) {
+	fmt.Printf("%s (screenboard %d)\n%s\n", screenboard.BoardTitle, screenboard.ID, url)
+	if len(screenboard.TemplateVariables) > 0 {
+		fmt.Println("\nTemplate variables:")
+		for _, v := range screenboard.TemplateVariables {
+			m, _ := v.(map[string]interface{})
+			fmt.Printf("  $%v (prefix %v, default %v)\n", m["name"], m["prefix"], m["default"])
+		}
+	}
+	fmt.Println()
+	n := 0
+	for _, w := range screenboard.Widgets {
+		// skip notes, images and other widgets without queries
+		if len(w.TileDef.Requests) == 0 {
+			continue
+		}
+		n++
+		fmt.Printf("%d. %s [%s]\n", n, w.TitleText, w.TileDef.Viz)
+		for _, r := range w.TileDef.Requests {
+			fmt.Printf("   - %s\n", r.Q)
+		}
+	}
+}
+
+// tagReplacement replaces one tag with another when cloning a board.
+type tagReplacement struct {
+	From, To string
+}
+
+func (r tagReplacement) fromValue() string {
+	return r.From[strings.Index(r.From, ":")+1:]
+}
+
+func (r tagReplacement) toValue() string {
+	return r.To[strings.Index(r.To, ":")+1:]
+}
+
+func cloneDashboard(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("board id required")
+	}
+	id := c.Args()[0]
+	boardType := c.String("type")
+	if boardType != "" && boardType != "timeboard" && boardType != "screenboard" {
+		return errors.New("--type must be either timeboard or screenboard")
+	}
+	replacements := []tagReplacement{}
+	for _, r := range c.StringSlice("replace") {
+		parts := strings.SplitN(r, "=", 2)
+		if len(parts) != 2 || !strings.Contains(parts[0], ":") || !strings.Contains(parts[1], ":") {
+			return errors.New("invalid --replace, expected key:old=key:new: " + r)
+		}
+		replacements = append(replacements, tagReplacement{parts[0], parts[1]})
+	}
+
+	api := getAPI()
+	if boardType != "screenboard" {
+		dash, err := api.GetDashboardDefinition(id)
+		if err == nil {
+			dash.Title = cloneTitle(c.String("title"), dash.Title, replacements)
+			dash.ReadOnly = false
+			// the clone mustn't be matched to the original's file by plan
+			if managedID := plan.ManagedID(nil, dash.Description); managedID != "" {
+				dash.Description = strings.TrimSpace(strings.Replace(dash.Description, plan.ManagedIDPrefix+managedID, "", -1))
+			}
+			for _, g := range dash.Graphs {
+				replaceTitle(g, "title", replacements)
+			}
+			replaceInRequests(dash.Requests(), replacements)
+			replaceTemplateVariables(dash.TemplateVariables, replacements)
+
+			created, err := api.CreateDashboard(*dash)
+			if err != nil {
+				return errors.New("failed to create timeboard: " + err.Error())
+			}
+			log.Printf("Created timeboard %d %q %s", created.ID, created.Title, api.DashboardURL(strconv.Itoa(created.ID)))
+			return nil
+		}
+		if boardType == "timeboard" {
+			return err
+		}
+	}
+
+	screenboardID, err := strconv.Atoi(id)
+	if err != nil {
+		return errors.New("invalid screenboard id " + id)
+	}
+	screenboard, err := api.GetScreenboardDefinition(screenboardID)
+	if err != nil {
+		return err
+	}
+	screenboard.BoardTitle = cloneTitle(c.String("title"), screenboard.BoardTitle, replacements)
+	screenboard.ReadOnly = false
+	for _, w := range screenboard.Widgets {
+		replaceTitle(w, "title_text", replacements)
+	}
+	replaceInRequests(screenboard.Requests(), replacements)
+	replaceTemplateVariables(screenboard.TemplateVariables, replacements)
+
+	created, err := api.CreateScreenboard(*screenboard)
+	if err != nil {
+		return errors.New("failed to create screenboard: " + err.Error())
+	}
+	log.Printf("Created screenboard %d %q %s", created.ID, created.BoardTitle, api.ScreenboardURL(created.ID))
+	return nil
+}
+
+// cloneTitle returns title if set, otherwise the original title with the
+// replaced tags' values replaced where they're whole words, so replacing api
+// doesn't change "Rapid".
+func cloneTitle(title string, original string, replacements []tagReplacement) string {
+	if title != "" {
+		return title
+	}
+	for _, r := range replacements {
+		original = replaceWhole(original, r.fromValue(), r.toValue(), isWordChar)
+	}
+	return original
+}
+
+// replaceTitle replaces the replaced tags' values in a graph or widget's
+// title, held in key.
+func replaceTitle(graph map[string]interface{}, key string, replacements []tagReplacement) {
+	if title, ok := graph[key].(string); ok && title != "" {
+		graph[key] = cloneTitle("", title, replacements)
+	}
+}
+
+// replaceInRequests replaces tags in the requests' queries. Tags the parsed
+// query doesn't have, such as in queries that can't be parsed, are replaced
+// as text where they're whole tags, so service:old doesn't change
+// service:old-canary.
+func replaceInRequests(requests []datadog.GraphRequest, replacements []tagReplacement) {
+	for _, r := range requests {
+		q, ok := r.Request["q"].(string)
+		if !ok {
+			continue
+		}
+		after, _, err := query.Rewrite(q, func(expr query.Expr) bool {
+			changed := false
+			for _, rep := range replacements {
+				if query.RenameTag(expr, rep.From, rep.To) {
+					changed = true
+				}
+			}
+			return changed
+		})
+		if err != nil {
+			after = q
+		}
+		// tags left in queries that can't be parsed, or in boolean scopes like
+		// {service:old AND env:prod}, are replaced as text
+		for _, rep := range replacements {
+			after = replaceWhole(after, rep.From, rep.To, isTagChar)
+		}
+		if after != q {
+			r.Request["q"] = after
+		}
+	}
+}
+
+// replaceTemplateVariables replaces the defaults of template variables that
+// default to a replaced tag, e.g. the service variable defaulting to old.
+func replaceTemplateVariables(variables []map[string]interface{}, replacements []tagReplacement) {
+	for _, v := range variables {
+		prefix, _ := v["prefix"].(string)
+		def, _ := v["default"].(string)
+		for _, r := range replacements {
+			if def == r.From {
+				v["default"] = r.To
+			} else if prefix != "" && prefix+":"+def == r.From {
+				v["default"] = r.toValue()
+			}
+		}
+	}
+}
+
+// replaceWhole replaces from with to in s wherever it isn't next to a
+// character that inside says would make it part of something longer.
+func replaceWhole(s string, from string, to string, inside func(byte) bool) string {
+	if from == "" {
+		return s
+	}
+	replaced := ""
+	start := 0
+	for i := indexWhole(s, from, 0, inside); i >= 0; i = indexWhole(s, from, start, inside) {
+		replaced += s[start:i] + to
+		start = i + len(from)
+	}
+	return replaced + s[start:]
+}
+
+// containsWhole returns whether s has from in it where replaceWhole would
+// replace it.
+func containsWhole(s string, from string, inside func(byte) bool) bool {
+	return from != "" && indexWhole(s, from, 0, inside) >= 0
+}
+
+// indexWhole returns the index of the first whole from in s at or after i, or
+// -1 if there isn't one.
+func indexWhole(s string, from string, i int, inside func(byte) bool) int {
+	for i < len(s) {
+		j := strings.Index(s[i:], from)
+		if j < 0 {
+			return -1
+		}
+		j += i
+		end := j + len(from)
+		if (j == 0 || !inside(s[j-1])) && (end == len(s) || !inside(s[end])) {
+			return j
+		}
+		i = j + 1
+	}
+	return -1
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+// isTagChar returns whether c can be part of a tag, e.g. service:old-canary.
+func isTagChar(c byte) bool {
+	return isWordChar(c) || strings.IndexByte("-.:/", c) >= 0
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneTitle(t *testing.T) {
+	reps := []tagReplacement{{"service:api", "service:web"}}
+	tests := []struct {
+		title    string
+		original string
+		expected string
+	}{
+		{"", "Rapid API", "Rapid API"},
+		{"", "api overview", "web overview"},
+		{"", "api: latency (api)", "web: latency (web)"},
+		{"", "api-canary", "web-canary"},
+		{"", "apis", "apis"},
+		{"Payments", "api overview", "Payments"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, cloneTitle(test.title, test.original, reps), test.original)
+	}
+}
+
+func TestReplaceInRequests(t *testing.T) {
+	reps := []tagReplacement{{"service:old", "service:new"}}
+	requests := []datadog.GraphRequest{
+		{Request: map[string]interface{}{"q": "sum:app.requests{service:old,env:prod} by {host}"}},
+		{Request: map[string]interface{}{"q": "sum:app.requests{service:old-canary}"}},
+		{Request: map[string]interface{}{"q": "sum:app.requests{!service:old}"}},
+		// unparseable, so replaced as text
+		{Request: map[string]interface{}{"q": "sum:app.requests{service:old, service:old-canary} by {host"}},
+		{Request: map[string]interface{}{"q": "sum:app.requests{!service:old,a-service:old} by {host"}},
+		// parsed, but boolean scopes are a single tag
+		{Request: map[string]interface{}{"q": "sum:app.requests{service:old AND env:prod}.rollup(sum, 60)"}},
+		{Request: map[string]interface{}{"q": "sum:app.requests{service:old}, sum:app.errors{service:old OR service:old-canary}"}},
+		{Request: map[string]interface{}{"log_query": map[string]interface{}{"search": "service:old"}}},
+	}
+	replaceInRequests(requests, reps)
+
+	expected := []string{
+		"sum:app.requests{service:new,env:prod} by {host}",
+		"sum:app.requests{service:old-canary}",
+		"sum:app.requests{!service:new}",
+		"sum:app.requests{service:new, service:old-canary} by {host",
+		"sum:app.requests{!service:new,a-service:old} by {host",
+		"sum:app.requests{service:new AND env:prod}.rollup(sum, 60)",
+		"sum:app.requests{service:new}, sum:app.errors{service:new OR service:old-canary}",
+	}
+	for i, q := range expected {
+		require.Equal(t, q, requests[i].Request["q"])
+	}
+	require.Nil(t, requests[7].Request["q"])
+}
+
+func TestReplaceTemplateVariables(t *testing.T) {
+	reps := []tagReplacement{{"service:old", "service:new"}}
+	variables := []map[string]interface{}{
+		{"name": "service", "prefix": "service", "default": "old"},
+		{"name": "scope", "default": "service:old"},
+		{"name": "other", "prefix": "team", "default": "old"},
+		{"name": "canary", "prefix": "service", "default": "old-canary"},
+		{"name": "empty", "prefix": "service"},
+	}
+	replaceTemplateVariables(variables, reps)
+	require.Equal(t, "new", variables[0]["default"])
+	require.Equal(t, "service:new", variables[1]["default"])
+	require.Equal(t, "old", variables[2]["default"])
+	require.Equal(t, "old-canary", variables[3]["default"])
+	require.Nil(t, variables[4]["default"])
+}
@@ -10,6 +10,7 @@ type DashboardSummary struct {
 	Title       string    `json:"title"`
 	Created     time.Time `json:"created"`
 	Modified    time.Time `json:"modified"`
+	CreatedBy   *Creator  `json:"created_by,omitempty"`
 }
 
 type Dashboard struct {
@@ -35,3 +36,45 @@ type Dashboard struct {
 		Name    string `json:"name"`
 	} `json:"template_variables"`
 }
+
+// DashboardDefinition holds the fields of a new dashboard. Graphs and template
+// variables are kept as given, as graph definitions vary by visualisation.
+type DashboardDefinition struct {
+	Title             string                   `json:"title"`
+	Description       string                   `json:"description"`
+	ReadOnly          bool                     `json:"read_only,omitempty"`
+	Graphs            []map[string]interface{} `json:"graphs"`
+	TemplateVariables []map[string]interface{} `json:"template_variables,omitempty"`
+}
+
+// GraphRequest is a request of a graph or widget, kept as given so that it can
+// be changed in place, e.g. Request["q"] = newQuery.
+type GraphRequest struct {
+	// Title is the title of the graph or widget the request belongs to.
+	Title   string
+	Request map[string]interface{}
+}
+
+// Requests returns the requests of every graph on the dashboard.
+func (d DashboardDefinition) Requests() []GraphRequest {
+	requests := []GraphRequest{}
+	for _, g := range d.Graphs {
+		title, _ := g["title"].(string)
+		requests = append(requests, graphRequests(title, g["definition"])...)
+	}
+	return requests
+}
+
+// graphRequests returns the requests in a decoded graph definition or widget
+// tile_def.
+func graphRequests(title string, definition interface{}) []GraphRequest {
+	def, _ := definition.(map[string]interface{})
+	list, _ := def["requests"].([]interface{})
+	requests := []GraphRequest{}
+	for _, r := range list {
+		if request, ok := r.(map[string]interface{}); ok {
+			requests = append(requests, GraphRequest{title, request})
+		}
+	}
+	return requests
+}
@@ -1,6 +1,7 @@
 package datadog
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -19,10 +21,16 @@ type API struct {
 }
 
 func New(apiKey string, appKey string) *API {
+	return NewWithBaseURL(apiKey, appKey, "https://app.datadoghq.com")
+}
+
+// NewWithBaseURL returns an API for another Datadog site, such as
+// https://app.datadoghq.eu, or a test server.
+func NewWithBaseURL(apiKey string, appKey string, baseURL string) *API {
 	return &API{
 		apiKey:  apiKey,
 		appKey:  appKey,
-		baseURL: "https://app.datadoghq.com",
+		baseURL: baseURL,
 	}
 }
 
@@ -100,6 +108,11 @@ func (d API) GetDashboard(id string) (*Dashboard, error) {
 	return &respObj.Dash, nil
 }
 
+// DashboardURL returns the address of a timeboard in the Datadog app.
+func (d API) DashboardURL(id string) string {
+	return d.baseURL + "/dash/" + url.PathEscape(id)
+}
+
 func (d API) GetScreenboards() ([]ScreenboardSummary, error) {
 	req, err := d.newRequest("GET", "/api/v1/screen", nil)
 	if err != nil {
@@ -169,6 +182,11 @@ func (d API) GetScreenboard(id int) (*Screenboard, error) {
 	return screenboard, nil
 }
 
+// ScreenboardURL returns the address of a screenboard in the Datadog app.
+func (d API) ScreenboardURL(id int) string {
+	return fmt.Sprintf("%s/screen/%d", d.baseURL, id)
+}
+
 func (d API) GetMonitors() ([]Monitor, error) {
 	req, err := d.newRequest("GET", "/api/v1/monitor", nil)
 	if err != nil {
@@ -203,6 +221,116 @@ func (d API) GetMonitors() ([]Monitor, error) {
 	return monitors, nil
 }
 
+// GetMonitor returns the monitor with the given ID, including the state of
+// each of its groups.
+func (d API) GetMonitor(id int) (*Monitor, error) {
+	query := url.Values{}
+	query.Set("group_states", "all")
+	monitor := new(Monitor)
+	if err := d.doJSON(http.MethodGet, fmt.Sprintf("/api/v1/monitor/%d", id), query, nil, monitor); err != nil {
+		return nil, err
+	}
+	return monitor, nil
+}
+
+// UpdateMonitor updates the monitor with the given ID, only changing the
+// fields that are set in update.
+func (d API) UpdateMonitor(id int, update MonitorUpdate) (*Monitor, error) {
+	monitor := new(Monitor)
+	if err := d.doJSON(http.MethodPut, fmt.Sprintf("/api/v1/monitor/%d", id), nil, update, monitor); err != nil {
+		return nil, err
+	}
+	return monitor, nil
+}
+
+func (d API) CreateMonitor(def MonitorDefinition) (*Monitor, error) {
+	monitor := new(Monitor)
+	if err := d.doJSON(http.MethodPost, "/api/v1/monitor", nil, def, monitor); err != nil {
+		return nil, err
+	}
+	return monitor, nil
+}
+
+// GetMonitorDefinition returns the fields of a monitor that can be set when
+// creating or updating it, keeping all of its options.
+func (d API) GetMonitorDefinition(id int) (*MonitorDefinition, error) {
+	def := new(MonitorDefinition)
+	if err := d.doJSON(http.MethodGet, fmt.Sprintf("/api/v1/monitor/%d", id), nil, nil, def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (d API) DeleteMonitor(id int) error {
+	return d.doJSON(http.MethodDelete, fmt.Sprintf("/api/v1/monitor/%d", id), nil, nil, nil)
+}
+
+func (d API) CreateDashboard(def DashboardDefinition) (*Dashboard, error) {
+	resp := struct {
+		Dash Dashboard `json:"dash"`
+	}{}
+	if err := d.doJSON(http.MethodPost, "/api/v1/dash", nil, def, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Dash, nil
+}
+
+// GetDashboardDefinition returns the fields of a dashboard that can be set when
+// creating or updating it, keeping all of its graph definitions.
+func (d API) GetDashboardDefinition(id string) (*DashboardDefinition, error) {
+	resp := struct {
+		Dash DashboardDefinition `json:"dash"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/dash/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Dash, nil
+}
+
+func (d API) UpdateDashboard(id string, def DashboardDefinition) (*Dashboard, error) {
+	resp := struct {
+		Dash Dashboard `json:"dash"`
+	}{}
+	if err := d.doJSON(http.MethodPut, "/api/v1/dash/"+url.PathEscape(id), nil, def, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Dash, nil
+}
+
+func (d API) DeleteDashboard(id string) error {
+	return d.doJSON(http.MethodDelete, "/api/v1/dash/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// GetScreenboardDefinition returns the fields of a screenboard that can be set
+// when creating or updating it, keeping all of its widget definitions.
+func (d API) GetScreenboardDefinition(id int) (*ScreenboardDefinition, error) {
+	def := new(ScreenboardDefinition)
+	if err := d.doJSON(http.MethodGet, fmt.Sprintf("/api/v1/screen/%d", id), nil, nil, def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+func (d API) CreateScreenboard(def ScreenboardDefinition) (*Screenboard, error) {
+	screenboard := new(Screenboard)
+	if err := d.doJSON(http.MethodPost, "/api/v1/screen", nil, def, screenboard); err != nil {
+		return nil, err
+	}
+	return screenboard, nil
+}
+
+func (d API) UpdateScreenboard(id int, def ScreenboardDefinition) (*Screenboard, error) {
+	screenboard := new(Screenboard)
+	if err := d.doJSON(http.MethodPut, fmt.Sprintf("/api/v1/screen/%d", id), nil, def, screenboard); err != nil {
+		return nil, err
+	}
+	return screenboard, nil
+}
+
+func (d API) DeleteScreenboard(id int) error {
+	return d.doJSON(http.MethodDelete, fmt.Sprintf("/api/v1/screen/%d", id), nil, nil, nil)
+}
+
 func (d API) GetMetrics(since time.Time) ([]string, error) {
 	req, err := d.newRequest(http.MethodGet, "/api/v1/metrics", nil)
 	if err != nil {
@@ -234,14 +362,20 @@ func (d API) GetMetrics(since time.Time) ([]string, error) {
 	return metricsResp.Metrics, nil
 }
 
+// GetTopAverageMetrics returns the top 500 custom metrics for the current month.
 func (d API) GetTopAverageMetrics() ([]MetricsUsage, error) {
+	return d.GetTopAverageMetricsForMonth(time.Now())
+}
+
+// GetTopAverageMetricsForMonth returns the top 500 custom metrics for the
+// month containing month.
+func (d API) GetTopAverageMetricsForMonth(month time.Time) ([]MetricsUsage, error) {
 	req, err := d.newRequest(http.MethodGet, "/api/v1/usage/top_avg_metrics", nil)
 	if err != nil {
 		return nil, err
 	}
-	// TODO way to set month
 	q := req.URL.Query()
-	q.Set("month", time.Now().Format("2006-01"))
+	q.Set("month", month.Format("2006-01"))
 	req.URL.RawQuery = q.Encode()
 
 	resp, err := http.DefaultClient.Do(req)
@@ -266,6 +400,9 @@ func (d API) GetTopAverageMetrics() ([]MetricsUsage, error) {
 	return r.Usage, nil
 }
 
+// newRequest creates a request to endpoint with the API and application keys
+// set both in the query string, for v1 endpoints, and as headers, which v2
+// endpoints such as /api/v2/metrics require.
 func (d API) newRequest(method string, endpoint string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, d.baseURL+endpoint, body)
 	if err != nil {
@@ -275,5 +412,66 @@ func (d API) newRequest(method string, endpoint string, body io.Reader) (*http.R
 	values.Add("api_key", d.apiKey)
 	values.Add("application_key", d.appKey)
 	req.URL.RawQuery = values.Encode()
+	req.Header.Set("DD-API-KEY", d.apiKey)
+	req.Header.Set("DD-APPLICATION-KEY", d.appKey)
 	return req, nil
 }
+
+// doJSON sends a request to the API, JSON encoding in as the request body if
+// it is not nil, and JSON decodes the response body into out if it is not nil.
+func (d API) doJSON(method string, endpoint string, query url.Values, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return errors.New("failed to marshal request: " + err.Error())
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := d.newRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(query) > 0 {
+		q := req.URL.Query()
+		for k, vs := range query {
+			for _, v := range vs {
+				q.Add(k, v)
+			}
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s %s response: %s", method, endpoint, err.Error())
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errResp := struct {
+			Errors []string `json:"errors"`
+		}{}
+		if json.Unmarshal(b, &errResp) == nil && len(errResp.Errors) > 0 {
+			return fmt.Errorf("received status code %d for %s %s: %s", resp.StatusCode, method, endpoint, strings.Join(errResp.Errors, ", "))
+		}
+		return fmt.Errorf("received status code %d for %s %s", resp.StatusCode, method, endpoint)
+	}
+
+	if out == nil || len(b) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("failed to parse %s %s response: %s", method, endpoint, err.Error())
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package datadog
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -87,6 +88,12 @@ func TestGetDashboards(t *testing.T) {
 			Title:       "Title 1",
 			Created:     time.Date(2016, 6, 23, 4, 47, 42, 419919000, time.UTC),
 			Modified:    time.Date(2018, 8, 30, 0, 39, 37, 132905000, time.UTC),
+			CreatedBy: &Creator{
+				Handle:   "email1@example.com",
+				Name:     "Example Name",
+				Email:    "email1@example.com",
+				Disabled: true,
+			},
 		},
 		{
 			ID:          "56724",
@@ -96,6 +103,11 @@ func TestGetDashboards(t *testing.T) {
 			Title:       "Title 2",
 			Created:     time.Date(2015, 6, 25, 7, 36, 2, 389983000, time.UTC),
 			Modified:    time.Date(2015, 06, 25, 8, 7, 33, 876645000, time.UTC),
+			CreatedBy: &Creator{
+				Handle: "email1@example.com",
+				Name:   "Example Name",
+				Email:  "email1@example.com",
+			},
 		},
 	}
 
@@ -109,6 +121,58 @@ func TestGetDashboards(t *testing.T) {
 	require.Equal(t, expected, summaries)
 }
 
+func TestGetScreenboards(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/screen", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"screenboards": [
+			  {
+				"id": 308,
+				"resource": "/api/v1/screen/308",
+				"title": "Ops",
+				"read_only": false,
+				"created": "2016-06-23T04:47:42.419919+00:00",
+				"modified": "2018-08-30T00:39:37.132905+00:00",
+				"created_by": {
+				  "disabled": false,
+				  "handle": "email2@example.com",
+				  "name": "Other Name",
+				  "email": "email2@example.com"
+				}
+			  },
+			  {
+				"id": 309,
+				"resource": "/api/v1/screen/309",
+				"title": "Old",
+				"read_only": true,
+				"created": "2015-06-25T07:36:02.389983+00:00",
+				"modified": "2015-06-25T08:07:33.876645+00:00"
+			  }
+			]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	summaries, err := api.GetScreenboards()
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, summaries, 2)
+	require.Equal(t, 308, summaries[0].ID)
+	require.Equal(t, &Creator{Handle: "email2@example.com", Name: "Other Name", Email: "email2@example.com"}, summaries[0].CreatedBy)
+	require.Nil(t, summaries[1].CreatedBy)
+}
+
 func TestGetMetrics(t *testing.T) {
 	requestCount := 0
 	handler := func(w http.ResponseWriter, r *http.Request) {
@@ -204,3 +268,383 @@ func TestGetTopAverageMetrics(t *testing.T) {
 
 	require.Equal(t, expected, usage)
 }
+
+func TestUpdateMonitor(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "PUT", r.Method)
+		require.Equal(t, "/api/v1/monitor/2081", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"message": "CPU is high @slack-new",
+			"tags": ["team:new"]
+		}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": 2081,
+			"name": "High CPU",
+			"type": "metric alert",
+			"query": "avg(last_5m):avg:system.cpu.user{*} > 90",
+			"message": "CPU is high @slack-new",
+			"tags": ["team:new"]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	message := "CPU is high @slack-new"
+	tags := []string{"team:new"}
+	monitor, err := api.UpdateMonitor(2081, MonitorUpdate{
+		Message: &message,
+		Tags:    &tags,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, 2081, monitor.ID)
+	require.Equal(t, message, monitor.Message)
+	require.Equal(t, tags, monitor.Tags)
+}
+
+func TestGetTopAverageMetricsForMonth(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "/api/v1/usage/top_avg_metrics", r.URL.Path)
+		require.Equal(t, "2026-09", r.URL.Query().Get("month"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"usage": [{"metric_category": "custom", "metric_name": "custom.metric.1", "max_metric_hour": 2, "avg_metric_hour": 1}]}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	usage, err := api.GetTopAverageMetricsForMonth(time.Date(2026, 9, 30, 23, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, []MetricsUsage{{Category: "custom", Name: "custom.metric.1", MaxPerHour: 2, AvgPerHour: 1}}, usage)
+}
+
+func TestGetMonitor(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/monitor/2081", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+		require.Equal(t, "all", r.URL.Query().Get("group_states"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": 2081,
+			"name": "Nightly export",
+			"type": "service check",
+			"query": "\"batch.nightly_export\".over(\"*\").by(\"host\").last(2).count_by_status()",
+			"overall_state": "Alert",
+			"state": {
+				"groups": {
+					"host:worker-1": {
+						"name": "host:worker-1",
+						"status": "Alert",
+						"last_triggered_ts": 1545717600,
+						"last_notified_ts": 1545717600,
+						"last_resolved_ts": 1545631200,
+						"last_nodata_ts": 0
+					}
+				}
+			}
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	monitor, err := api.GetMonitor(2081)
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, "service check", monitor.Type)
+	require.Equal(t, &MonitorState{
+		Groups: map[string]MonitorGroupState{
+			"host:worker-1": {
+				Name:            "host:worker-1",
+				Status:          "Alert",
+				LastTriggeredTS: 1545717600,
+				LastNotifiedTS:  1545717600,
+				LastResolvedTS:  1545631200,
+			},
+		},
+	}, monitor.State)
+}
+
+func TestDeleteObjects(t *testing.T) {
+	requests := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/screen/404" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors": ["Screenboard not found"]}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	require.NoError(t, api.DeleteMonitor(2081))
+	require.NoError(t, api.DeleteDashboard("150947"))
+	require.NoError(t, api.DeleteScreenboard(308))
+	err := api.DeleteScreenboard(404)
+	require.EqualError(t, err, "received status code 404 for DELETE /api/v1/screen/404: Screenboard not found")
+	require.Equal(t, []string{
+		"DELETE /api/v1/monitor/2081",
+		"DELETE /api/v1/dash/150947",
+		"DELETE /api/v1/screen/308",
+		"DELETE /api/v1/screen/404",
+	}, requests)
+}
+
+func TestCreateMonitorAndDashboard(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST", r.Method)
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/monitor":
+			require.JSONEq(t, `{
+				"name": "Checkout errors",
+				"type": "metric alert",
+				"query": "avg(last_5m):sum:checkout.errors{env:prod} > 10",
+				"message": "@slack-checkout",
+				"tags": ["service:checkout"],
+				"options": {"thresholds": {"critical": 10, "warning": 5}}
+			}`, string(b))
+			fmt.Fprint(w, `{"id": 3001, "name": "Checkout errors", "type": "metric alert"}`)
+		case "/api/v1/dash":
+			require.JSONEq(t, `{
+				"title": "Checkout",
+				"description": "",
+				"graphs": [{"title": "Errors", "definition": {"viz": "timeseries", "requests": [{"q": "sum:checkout.errors{*}"}]}}]
+			}`, string(b))
+			fmt.Fprint(w, `{"dash": {"id": 4001, "title": "Checkout"}, "url": "/dash/dash/4001"}`)
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	monitor, err := api.CreateMonitor(MonitorDefinition{
+		Name:    "Checkout errors",
+		Type:    "metric alert",
+		Query:   "avg(last_5m):sum:checkout.errors{env:prod} > 10",
+		Message: "@slack-checkout",
+		Tags:    []string{"service:checkout"},
+		Options: map[string]interface{}{
+			"thresholds": map[string]interface{}{"critical": 10, "warning": 5},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3001, monitor.ID)
+
+	dash, err := api.CreateDashboard(DashboardDefinition{
+		Title: "Checkout",
+		Graphs: []map[string]interface{}{
+			{"title": "Errors", "definition": map[string]interface{}{"viz": "timeseries", "requests": []interface{}{map[string]interface{}{"q": "sum:checkout.errors{*}"}}}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4001, dash.ID)
+}
+
+func TestDefinitions(t *testing.T) {
+	requests := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/monitor/2081":
+			fmt.Fprint(w, `{
+				"id": 2081,
+				"name": "CPU",
+				"type": "metric alert",
+				"query": "avg(last_5m):avg:system.cpu.user{*} > 90",
+				"message": "",
+				"tags": ["team:ops"],
+				"overall_state": "OK",
+				"options": {"thresholds": {"critical": 90, "warning": 80}, "notify_no_data": false}
+			}`)
+		case "GET /api/v1/dash/150947":
+			fmt.Fprint(w, `{"dash": {"id": 150947, "title": "Hosts", "description": "", "graphs": [{"title": "CPU", "definition": {"viz": "heatmap"}}]}}`)
+		case "PUT /api/v1/dash/150947":
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"title": "Hosts v2", "description": "", "graphs": []}`, string(b))
+			fmt.Fprint(w, `{"dash": {"id": 150947, "title": "Hosts v2"}}`)
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	monitor, err := api.GetMonitorDefinition(2081)
+	require.NoError(t, err)
+	require.Equal(t, "CPU", monitor.Name)
+	require.Equal(t, map[string]interface{}{
+		"thresholds":     map[string]interface{}{"critical": 90.0, "warning": 80.0},
+		"notify_no_data": false,
+	}, monitor.Options)
+
+	dash, err := api.GetDashboardDefinition("150947")
+	require.NoError(t, err)
+	require.Equal(t, "Hosts", dash.Title)
+	require.Equal(t, "heatmap", dash.Graphs[0]["definition"].(map[string]interface{})["viz"])
+
+	updated, err := api.UpdateDashboard("150947", DashboardDefinition{Title: "Hosts v2", Graphs: []map[string]interface{}{}})
+	require.NoError(t, err)
+	require.Equal(t, "Hosts v2", updated.Title)
+	require.Equal(t, []string{"GET /api/v1/monitor/2081", "GET /api/v1/dash/150947", "PUT /api/v1/dash/150947"}, requests)
+}
+
+func TestBoardURLs(t *testing.T) {
+	api := New("api-key", "app-key")
+	require.Equal(t, "https://app.datadoghq.com/dash/150947", api.DashboardURL("150947"))
+	require.Equal(t, "https://app.datadoghq.com/screen/308", api.ScreenboardURL(308))
+}
+
+func TestScreenboardDefinition(t *testing.T) {
+	requests := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/screen/308":
+			fmt.Fprint(w, `{
+				"id": 308,
+				"board_title": "Checkout",
+				"width": "100%",
+				"height": 60,
+				"widgets": [
+					{"type": "note", "title_text": "", "html": "hello"},
+					{"type": "timeseries", "title_text": "Requests", "tile_def": {"viz": "timeseries", "requests": [{"q": "sum:app.req.count{*}", "type": "line"}]}}
+				]
+			}`)
+		case "PUT /api/v1/screen/308":
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{
+				"board_title": "Checkout",
+				"width": "100%",
+				"height": 60,
+				"widgets": [
+					{"type": "note", "title_text": "", "html": "hello"},
+					{"type": "timeseries", "title_text": "Requests", "tile_def": {"viz": "timeseries", "requests": [{"q": "sum:app.requests.total{*}", "type": "line"}]}}
+				]
+			}`, string(b))
+			fmt.Fprint(w, `{"id": 308, "board_title": "Checkout"}`)
+		case "POST /api/v1/screen":
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"board_title": "Payments", "widgets": []}`, string(b))
+			fmt.Fprint(w, `{"id": 309, "board_title": "Payments"}`)
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	screenboard, err := api.GetScreenboardDefinition(308)
+	require.NoError(t, err)
+	require.Equal(t, "Checkout", screenboard.BoardTitle)
+	graphRequests := screenboard.Requests()
+	require.Len(t, graphRequests, 1)
+	require.Equal(t, "Requests", graphRequests[0].Title)
+	require.Equal(t, "sum:app.req.count{*}", graphRequests[0].Request["q"])
+
+	// requests are changed in place
+	graphRequests[0].Request["q"] = "sum:app.requests.total{*}"
+	updated, err := api.UpdateScreenboard(308, *screenboard)
+	require.NoError(t, err)
+	require.Equal(t, 308, updated.ID)
+
+	created, err := api.CreateScreenboard(ScreenboardDefinition{BoardTitle: "Payments", Widgets: []map[string]interface{}{}})
+	require.NoError(t, err)
+	require.Equal(t, 309, created.ID)
+	require.Equal(t, []string{"GET /api/v1/screen/308", "PUT /api/v1/screen/308", "POST /api/v1/screen"}, requests)
+}
+
+func TestDashboardDefinitionRequests(t *testing.T) {
+	dash := DashboardDefinition{
+		Graphs: []map[string]interface{}{
+			{"title": "CPU", "definition": map[string]interface{}{"requests": []interface{}{
+				map[string]interface{}{"q": "avg:system.cpu.user{*}"},
+				map[string]interface{}{"q": "avg:system.cpu.system{*}"},
+			}}},
+			{"title": "Empty", "definition": map[string]interface{}{}},
+		},
+	}
+	requests := dash.Requests()
+	require.Len(t, requests, 2)
+	require.Equal(t, "CPU", requests[1].Title)
+	require.Equal(t, "avg:system.cpu.system{*}", requests[1].Request["q"])
+}
+
+func TestNewRequestKeys(t *testing.T) {
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: "https://app.datadoghq.com",
+	}
+	req, err := api.newRequest(http.MethodGet, "/api/v2/metrics/a/all-tags", nil)
+	require.NoError(t, err)
+	require.Equal(t, "api-key", req.URL.Query().Get("api_key"))
+	require.Equal(t, "app-key", req.URL.Query().Get("application_key"))
+	require.Equal(t, "api-key", req.Header.Get("DD-API-KEY"))
+	require.Equal(t, "app-key", req.Header.Get("DD-APPLICATION-KEY"))
+}
@@ -0,0 +1,73 @@
+package datadog
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type Downtime struct {
+	ID          int                 `json:"id,omitempty"`
+	Active      bool                `json:"active,omitempty"`
+	Disabled    bool                `json:"disabled,omitempty"`
+	Canceled    *int64              `json:"canceled,omitempty"`
+	CreatorID   int                 `json:"creator_id,omitempty"`
+	Scope       []string            `json:"scope"`
+	MonitorID   *int                `json:"monitor_id,omitempty"`
+	MonitorTags []string            `json:"monitor_tags,omitempty"`
+	Start       int64               `json:"start,omitempty"`
+	End         *int64              `json:"end,omitempty"`
+	Timezone    string              `json:"timezone,omitempty"`
+	Message     string              `json:"message,omitempty"`
+	Recurrence  *DowntimeRecurrence `json:"recurrence,omitempty"`
+}
+
+// DowntimeRecurrence describes a repeating downtime. Either Type and Period
+// (with the optional fields that go with them) or RRule should be set.
+type DowntimeRecurrence struct {
+	Type             string   `json:"type"`
+	Period           int      `json:"period,omitempty"`
+	WeekDays         []string `json:"week_days,omitempty"`
+	UntilDate        *int64   `json:"until_date,omitempty"`
+	UntilOccurrences *int     `json:"until_occurrences,omitempty"`
+	RRule            string   `json:"rrule,omitempty"`
+}
+
+func (d API) GetDowntimes(currentOnly bool) ([]Downtime, error) {
+	query := url.Values{}
+	if currentOnly {
+		query.Set("current_only", "true")
+	}
+	downtimes := []Downtime{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/downtime", query, nil, &downtimes); err != nil {
+		return nil, err
+	}
+	return downtimes, nil
+}
+
+func (d API) CreateDowntime(downtime Downtime) (*Downtime, error) {
+	created := new(Downtime)
+	if err := d.doJSON(http.MethodPost, "/api/v1/downtime", nil, downtime, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (d API) CancelDowntime(id int) error {
+	return d.doJSON(http.MethodDelete, fmt.Sprintf("/api/v1/downtime/%d", id), nil, nil, nil)
+}
+
+// CancelDowntimesByScope cancels all downtimes matching scope, returning the
+// IDs of the downtimes that were cancelled.
+func (d API) CancelDowntimesByScope(scope string) ([]int, error) {
+	req := struct {
+		Scope string `json:"scope"`
+	}{scope}
+	resp := struct {
+		CancelledIDs []int `json:"cancelled_ids"`
+	}{}
+	if err := d.doJSON(http.MethodPost, "/api/v1/downtime/cancel/by_scope", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.CancelledIDs, nil
+}
@@ -0,0 +1,208 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDowntimes(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/downtime", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+		require.Equal(t, "true", r.URL.Query().Get("current_only"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{
+				"active": true,
+				"canceled": null,
+				"creator_id": 3658,
+				"disabled": false,
+				"end": 1412793983,
+				"id": 1625,
+				"message": "Deploying checkout",
+				"monitor_id": null,
+				"monitor_tags": ["team:payments"],
+				"recurrence": {
+					"period": 1,
+					"type": "weeks",
+					"week_days": ["Mon", "Tue"]
+				},
+				"scope": ["env:prod"],
+				"start": 1412792983
+			}
+		]`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	downtimes, err := api.GetDowntimes(true)
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+
+	end := int64(1412793983)
+	expected := []Downtime{
+		{
+			ID:          1625,
+			Active:      true,
+			CreatorID:   3658,
+			Scope:       []string{"env:prod"},
+			MonitorTags: []string{"team:payments"},
+			Start:       1412792983,
+			End:         &end,
+			Message:     "Deploying checkout",
+			Recurrence: &DowntimeRecurrence{
+				Type:     "weeks",
+				Period:   1,
+				WeekDays: []string{"Mon", "Tue"},
+			},
+		},
+	}
+	require.Equal(t, expected, downtimes)
+}
+
+func TestCreateDowntime(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/api/v1/downtime", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"scope": ["env:prod", "service:checkout"],
+			"monitor_id": 42,
+			"start": 1412792983,
+			"end": 1412793983,
+			"message": "Deploying checkout",
+			"recurrence": {"type": "rrule", "rrule": "FREQ=DAILY"}
+		}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": 1626,
+			"active": true,
+			"scope": ["env:prod", "service:checkout"],
+			"monitor_id": 42,
+			"start": 1412792983,
+			"end": 1412793983,
+			"message": "Deploying checkout",
+			"recurrence": {"type": "rrule", "rrule": "FREQ=DAILY"}
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	monitorID := 42
+	end := int64(1412793983)
+	downtime, err := api.CreateDowntime(Downtime{
+		Scope:     []string{"env:prod", "service:checkout"},
+		MonitorID: &monitorID,
+		Start:     1412792983,
+		End:       &end,
+		Message:   "Deploying checkout",
+		Recurrence: &DowntimeRecurrence{
+			Type:  "rrule",
+			RRule: "FREQ=DAILY",
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, 1626, downtime.ID)
+	require.True(t, downtime.Active)
+}
+
+func TestCancelDowntime(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "DELETE", r.Method)
+		require.Equal(t, "/api/v1/downtime/1625", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	require.NoError(t, api.CancelDowntime(1625))
+	require.Equal(t, 1, requestCount)
+}
+
+func TestCancelDowntimesByScope(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/api/v1/downtime/cancel/by_scope", r.URL.Path)
+
+		body := struct {
+			Scope string `json:"scope"`
+		}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "env:staging", body.Scope)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"cancelled_ids": [123456789, 123456790]}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	ids, err := api.CancelDowntimesByScope("env:staging")
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, []int{123456789, 123456790}, ids)
+}
+
+func TestCancelDowntimeError(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": ["Downtime not found"]}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	err := api.CancelDowntime(1)
+	require.EqualError(t, err, "received status code 404 for DELETE /api/v1/downtime/1: Downtime not found")
+}
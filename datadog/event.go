@@ -0,0 +1,79 @@
+package datadog
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Event struct {
+	ID             int64    `json:"id,omitempty"`
+	Title          string   `json:"title"`
+	Text           string   `json:"text"`
+	DateHappened   int64    `json:"date_happened,omitempty"`
+	Priority       string   `json:"priority,omitempty"`
+	Host           string   `json:"host,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	AlertType      string   `json:"alert_type,omitempty"`
+	AggregationKey string   `json:"aggregation_key,omitempty"`
+	SourceTypeName string   `json:"source_type_name,omitempty"`
+	URL            string   `json:"url,omitempty"`
+}
+
+// PostEvent submits an event, returning it as created.
+func (d API) PostEvent(event Event) (*Event, error) {
+	resp := struct {
+		Event Event `json:"event"`
+	}{}
+	if err := d.doJSON(http.MethodPost, "/api/v1/events", nil, event, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Event, nil
+}
+
+// EventFilters narrows down the events returned by GetEvents. Empty fields
+// match everything.
+type EventFilters struct {
+	// Priority is either normal or low.
+	Priority string
+	Sources  []string
+	Tags     []string
+}
+
+// eventsPageSize is the maximum number of events the API returns at once.
+const eventsPageSize = 1000
+
+// GetEvents returns the events that happened between start and end, newest
+// first, fetching every page of results.
+func (d API) GetEvents(start time.Time, end time.Time, filters EventFilters) ([]Event, error) {
+	query := url.Values{}
+	query.Set("start", strconv.FormatInt(start.Unix(), 10))
+	query.Set("end", strconv.FormatInt(end.Unix(), 10))
+	query.Set("unaggregated", "true")
+	if filters.Priority != "" {
+		query.Set("priority", filters.Priority)
+	}
+	if len(filters.Sources) > 0 {
+		query.Set("sources", strings.Join(filters.Sources, ","))
+	}
+	if len(filters.Tags) > 0 {
+		query.Set("tags", strings.Join(filters.Tags, ","))
+	}
+
+	events := []Event{}
+	for page := 0; ; page++ {
+		query.Set("page", strconv.Itoa(page))
+		resp := struct {
+			Events []Event `json:"events"`
+		}{}
+		if err := d.doJSON(http.MethodGet, "/api/v1/events", query, nil, &resp); err != nil {
+			return nil, err
+		}
+		events = append(events, resp.Events...)
+		if len(resp.Events) < eventsPageSize {
+			return events, nil
+		}
+	}
+}
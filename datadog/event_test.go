@@ -0,0 +1,136 @@
+package datadog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostEvent(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/api/v1/events", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"title": "Deployed checkout",
+			"text": "Version 1.2.3",
+			"tags": ["service:checkout"],
+			"alert_type": "info"
+		}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{
+			"status": "ok",
+			"event": {
+				"id": 1377281704830403917,
+				"title": "Deployed checkout",
+				"text": "Version 1.2.3",
+				"date_happened": 1545717600,
+				"priority": "normal",
+				"tags": ["service:checkout"],
+				"url": "https://app.datadoghq.com/event/event?id=1377281704830403917"
+			}
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	event, err := api.PostEvent(Event{
+		Title:     "Deployed checkout",
+		Text:      "Version 1.2.3",
+		Tags:      []string{"service:checkout"},
+		AlertType: "info",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, int64(1377281704830403917), event.ID)
+	require.Equal(t, "https://app.datadoghq.com/event/event?id=1377281704830403917", event.URL)
+}
+
+func TestGetEvents(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/events", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+		require.Equal(t, "1545631200", r.URL.Query().Get("start"))
+		require.Equal(t, "1545717600", r.URL.Query().Get("end"))
+		require.Equal(t, "low", r.URL.Query().Get("priority"))
+		require.Equal(t, "jenkins,chef", r.URL.Query().Get("sources"))
+		require.Equal(t, "env:prod", r.URL.Query().Get("tags"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "0" {
+			// a full page means there might be more
+			events := make([]string, eventsPageSize)
+			for i := range events {
+				events[i] = fmt.Sprintf(`{"id": %d, "title": "Event %d", "date_happened": 1545717000}`, i+1, i+1)
+			}
+			fmt.Fprint(w, `{"events": [`+strings.Join(events, ",")+`]}`)
+			return
+		}
+		require.Equal(t, "1", r.URL.Query().Get("page"))
+		fmt.Fprint(w, `{
+			"events": [
+				{
+					"id": 1377281704830403917,
+					"title": "Deployed checkout",
+					"text": "Version 1.2.3",
+					"date_happened": 1545631300,
+					"priority": "low",
+					"source_type_name": "jenkins",
+					"tags": ["env:prod"],
+					"alert_type": "success",
+					"url": "/event/event?id=1377281704830403917"
+				}
+			]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	events, err := api.GetEvents(time.Unix(1545631200, 0), time.Unix(1545717600, 0), EventFilters{
+		Priority: "low",
+		Sources:  []string{"jenkins", "chef"},
+		Tags:     []string{"env:prod"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, requestCount)
+	require.Len(t, events, eventsPageSize+1)
+	require.Equal(t, Event{
+		ID:             1377281704830403917,
+		Title:          "Deployed checkout",
+		Text:           "Version 1.2.3",
+		DateHappened:   1545631300,
+		Priority:       "low",
+		SourceTypeName: "jenkins",
+		Tags:           []string{"env:prod"},
+		AlertType:      "success",
+		URL:            "/event/event?id=1377281704830403917",
+	}, events[eventsPageSize])
+}
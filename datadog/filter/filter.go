@@ -0,0 +1,299 @@
+// Package filter parses expressions for selecting monitors, such as
+// `tag:team:old AND type:metric alert`.
+//
+// An expression is made up of field:value terms combined with AND, OR, NOT and
+// parentheses. Values run until the next keyword or parenthesis, so they may
+// contain spaces; double quotes can be used to include keywords or
+// parentheses in a value. The supported fields are:
+//
+//	id       exact monitor ID
+//	tag      exact tag, or a tag prefix if the value ends in *
+//	type     exact monitor type, e.g. "metric alert"
+//	state    exact overall state, e.g. "No Data"
+//	name     case insensitive substring of the monitor name
+//	query    substring of the monitor query
+//	message  substring of the monitor message
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/porty/ddcli/datadog"
+)
+
+// Expr is a parsed filter expression.
+type Expr interface {
+	Match(m datadog.Monitor) bool
+	String() string
+}
+
+var fields = map[string]bool{
+	"id":      true,
+	"tag":     true,
+	"type":    true,
+	"state":   true,
+	"name":    true,
+	"query":   true,
+	"message": true,
+}
+
+type term struct {
+	field string
+	value string
+}
+
+func (t term) Match(m datadog.Monitor) bool {
+	switch t.field {
+	case "id":
+		return strconv.Itoa(m.ID) == t.value
+	case "tag":
+		for _, tag := range m.Tags {
+			if strings.HasSuffix(t.value, "*") {
+				if strings.HasPrefix(tag, strings.TrimSuffix(t.value, "*")) {
+					return true
+				}
+			} else if tag == t.value {
+				return true
+			}
+		}
+		return false
+	case "type":
+		return m.Type == t.value
+	case "state":
+		return m.OverallState == t.value
+	case "name":
+		return strings.Contains(strings.ToLower(m.Name), strings.ToLower(t.value))
+	case "query":
+		return strings.Contains(m.Query, t.value)
+	case "message":
+		return strings.Contains(m.Message, t.value)
+	}
+	return false
+}
+
+func (t term) String() string {
+	return t.field + ":" + strconv.Quote(t.value)
+}
+
+type and struct {
+	left, right Expr
+}
+
+func (a and) Match(m datadog.Monitor) bool {
+	return a.left.Match(m) && a.right.Match(m)
+}
+
+func (a and) String() string {
+	return "(" + a.left.String() + " AND " + a.right.String() + ")"
+}
+
+type or struct {
+	left, right Expr
+}
+
+func (o or) Match(m datadog.Monitor) bool {
+	return o.left.Match(m) || o.right.Match(m)
+}
+
+func (o or) String() string {
+	return "(" + o.left.String() + " OR " + o.right.String() + ")"
+}
+
+type not struct {
+	expr Expr
+}
+
+func (n not) Match(m datadog.Monitor) bool {
+	return !n.expr.Match(m)
+}
+
+func (n not) String() string {
+	return "NOT " + n.expr.String()
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+func (t token) is(keyword string) bool {
+	return !t.quoted && t.text == keyword
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	var cur strings.Builder
+	inWord := false
+	quoted := false
+	inQuotes := false
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, token{text: cur.String(), quoted: quoted})
+		}
+		cur.Reset()
+		inWord = false
+		quoted = false
+	}
+
+	for _, r := range s {
+		switch {
+		case inQuotes:
+			if r == '"' {
+				inQuotes = false
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"':
+			// only a token starting with a quote is taken literally, so
+			// that field:"value" still parses as a term
+			quoted = quoted || !inWord
+			inQuotes = true
+			inWord = true
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, token{text: string(r)})
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("unterminated quote")
+	}
+	flush()
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Parse parses a filter expression.
+func Parse(s string) (Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	p := parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || !t.is("OR") {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = or{left, right}
+	}
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || !t.is("AND") {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = and{left, right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, errors.New("unexpected end of expression")
+	}
+	switch {
+	case t.is("NOT"):
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return not{expr}, nil
+	case t.is("("):
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		t, ok := p.peek()
+		if !ok || !t.is(")") {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *parser) parseTerm() (Expr, error) {
+	t := p.tokens[p.pos]
+	i := strings.Index(t.text, ":")
+	if t.quoted || i < 0 || !fields[t.text[:i]] {
+		return nil, fmt.Errorf("expected field:value, got %q", t.text)
+	}
+	p.pos++
+
+	words := []string{}
+	if t.text[i+1:] != "" {
+		words = append(words, t.text[i+1:])
+	}
+	for {
+		next, ok := p.peek()
+		if !ok || next.is("AND") || next.is("OR") || next.is("NOT") || next.is("(") || next.is(")") {
+			break
+		}
+		if j := strings.Index(next.text, ":"); !next.quoted && j >= 0 && fields[next.text[:j]] {
+			return nil, fmt.Errorf("missing AND or OR before %q", next.text)
+		}
+		words = append(words, next.text)
+		p.pos++
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("missing value for %q", t.text[:i])
+	}
+	return term{field: t.text[:i], value: strings.Join(words, " ")}, nil
+}
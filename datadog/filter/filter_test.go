@@ -0,0 +1,82 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`tag:team:old`, `tag:"team:old"`},
+		{`tag:team:old AND type:metric alert`, `(tag:"team:old" AND type:"metric alert")`},
+		{`name:cpu OR name:memory AND NOT state:OK`, `(name:"cpu" OR (name:"memory" AND NOT state:"OK"))`},
+		{`(name:cpu OR name:memory) AND state:No Data`, `((name:"cpu" OR name:"memory") AND state:"No Data")`},
+		{`message:"@slack-ops AND friends"`, `message:"@slack-ops AND friends"`},
+		{`tag:"env:prod"`, `tag:"env:prod"`},
+	}
+
+	for _, test := range tests {
+		expr, err := Parse(test.input)
+		require.NoError(t, err, test.input)
+		require.Equal(t, test.expected, expr.String(), test.input)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{``, `empty expression`},
+		{`tag:a tag:b`, `missing AND or OR before "tag:b"`},
+		{`colour:red`, `expected field:value, got "colour:red"`},
+		{`(tag:a`, `missing )`},
+		{`tag:a)`, `unexpected ")"`},
+		{`tag:a AND`, `unexpected end of expression`},
+		{`tag:`, `missing value for "tag"`},
+		{`name:"cpu`, `unterminated quote`},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		require.EqualError(t, err, test.expected, test.input)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	monitor := datadog.Monitor{
+		ID:           123,
+		Name:         "High CPU on {{host.name}}",
+		Type:         "metric alert",
+		Query:        "avg(last_5m):avg:system.cpu.user{env:prod} by {host} > 90",
+		Message:      "CPU is high @slack-old",
+		Tags:         []string{"team:old", "env:prod"},
+		OverallState: "No Data",
+	}
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`tag:team:old AND type:metric alert`, true},
+		{`tag:team:new AND type:metric alert`, false},
+		{`tag:team:* AND NOT tag:team:new`, true},
+		{`id:123`, true},
+		{`id:12`, false},
+		{`name:high cpu`, true},
+		{`query:system.cpu.user`, true},
+		{`message:@slack-new OR state:No Data`, true},
+		{`NOT (tag:env:prod OR tag:env:staging)`, false},
+	}
+
+	for _, test := range tests {
+		expr, err := Parse(test.input)
+		require.NoError(t, err, test.input)
+		require.Equal(t, test.expected, expr.Match(monitor), test.input)
+	}
+}
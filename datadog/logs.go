@@ -0,0 +1,83 @@
+package datadog
+
+import (
+	"net/http"
+	"time"
+)
+
+// LogsQuery describes which logs to search for.
+type LogsQuery struct {
+	Query   string
+	From    time.Time
+	To      time.Time
+	Indexes []string
+	// Limit is the number of logs per page, at most 1000.
+	Limit int
+	// Descending sorts the newest logs first.
+	Descending bool
+}
+
+type Log struct {
+	ID         string                 `json:"id"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Status     string                 `json:"status"`
+	Service    string                 `json:"service"`
+	Host       string                 `json:"host"`
+	Message    string                 `json:"message"`
+	Tags       []string               `json:"tags"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type logsSearchRequest struct {
+	Filter struct {
+		Query   string   `json:"query"`
+		From    string   `json:"from"`
+		To      string   `json:"to"`
+		Indexes []string `json:"indexes,omitempty"`
+	} `json:"filter"`
+	Sort string `json:"sort"`
+	Page struct {
+		Cursor string `json:"cursor,omitempty"`
+		Limit  int    `json:"limit,omitempty"`
+	} `json:"page"`
+}
+
+type logsSearchResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes Log    `json:"attributes"`
+	} `json:"data"`
+	Meta struct {
+		Page struct {
+			After string `json:"after"`
+		} `json:"page"`
+	} `json:"meta"`
+}
+
+// SearchLogs returns a page of logs matching q, starting from cursor if it
+// isn't empty. The returned cursor is empty when there are no more pages.
+func (d API) SearchLogs(q LogsQuery, cursor string) ([]Log, string, error) {
+	var req logsSearchRequest
+	req.Filter.Query = q.Query
+	req.Filter.From = q.From.UTC().Format(time.RFC3339Nano)
+	req.Filter.To = q.To.UTC().Format(time.RFC3339Nano)
+	req.Filter.Indexes = q.Indexes
+	req.Sort = "timestamp"
+	if q.Descending {
+		req.Sort = "-timestamp"
+	}
+	req.Page.Cursor = cursor
+	req.Page.Limit = q.Limit
+
+	var resp logsSearchResponse
+	if err := d.doJSON(http.MethodPost, "/api/v2/logs/events/search", nil, req, &resp); err != nil {
+		return nil, "", err
+	}
+
+	logs := make([]Log, len(resp.Data))
+	for i, data := range resp.Data {
+		logs[i] = data.Attributes
+		logs[i].ID = data.ID
+	}
+	return logs, resp.Meta.Page.After, nil
+}
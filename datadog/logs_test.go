@@ -0,0 +1,87 @@
+package datadog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchLogs(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/api/v2/logs/events/search", r.URL.Path)
+		require.Equal(t, "api-key", r.Header.Get("DD-API-KEY"))
+		require.Equal(t, "app-key", r.Header.Get("DD-APPLICATION-KEY"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"filter": {
+				"query": "service:api status:error",
+				"from": "2018-12-25T06:00:00Z",
+				"to": "2018-12-25T06:15:00Z",
+				"indexes": ["main"]
+			},
+			"sort": "timestamp",
+			"page": {"cursor": "abc", "limit": 2}
+		}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"data": [
+				{
+					"id": "AQAAAWf",
+					"type": "log",
+					"attributes": {
+						"timestamp": "2018-12-25T06:01:02.345Z",
+						"status": "error",
+						"service": "api",
+						"host": "web-1",
+						"message": "Request failed",
+						"tags": ["env:prod"],
+						"attributes": {"http": {"status_code": 500}}
+					}
+				}
+			],
+			"meta": {"page": {"after": "def"}}
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	logs, cursor, err := api.SearchLogs(LogsQuery{
+		Query:   "service:api status:error",
+		From:    time.Date(2018, 12, 25, 6, 0, 0, 0, time.UTC),
+		To:      time.Date(2018, 12, 25, 6, 15, 0, 0, time.UTC),
+		Indexes: []string{"main"},
+		Limit:   2,
+	}, "abc")
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, "def", cursor)
+	require.Len(t, logs, 1)
+
+	require.Equal(t, time.Date(2018, 12, 25, 6, 1, 2, 345000000, time.UTC), logs[0].Timestamp.UTC())
+	logs[0].Timestamp = time.Time{}
+	require.Equal(t, Log{
+		ID:         "AQAAAWf",
+		Status:     "error",
+		Service:    "api",
+		Host:       "web-1",
+		Message:    "Request failed",
+		Tags:       []string{"env:prod"},
+		Attributes: map[string]interface{}{"http": map[string]interface{}{"status_code": float64(500)}},
+	}, logs[0])
+}
@@ -0,0 +1,210 @@
+package datadog
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type LogsPipeline struct {
+	// Raw is the pipeline as returned by the API, including fields not
+	// modelled here, for exporting it without losing anything.
+	Raw        json.RawMessage `json:"-"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Name       string          `json:"name"`
+	IsEnabled  bool            `json:"is_enabled"`
+	IsReadOnly bool            `json:"is_read_only"`
+	Filter     LogsFilter      `json:"filter"`
+	Processors []LogsProcessor `json:"processors"`
+}
+
+type LogsFilter struct {
+	Query string `json:"query"`
+}
+
+// LogsProcessor is a step in a log pipeline. Processors have different
+// settings depending on their type, so anything other than the common fields
+// is kept in Settings.
+type LogsProcessor struct {
+	Type      string
+	Name      string
+	IsEnabled bool
+	// Filter and Processors are only used by nested pipelines.
+	Filter     *LogsFilter
+	Processors []LogsProcessor
+	Settings   map[string]interface{}
+}
+
+func (p *LogsProcessor) UnmarshalJSON(b []byte) error {
+	common := struct {
+		Type       string          `json:"type"`
+		Name       string          `json:"name"`
+		IsEnabled  bool            `json:"is_enabled"`
+		Filter     *LogsFilter     `json:"filter"`
+		Processors []LogsProcessor `json:"processors"`
+	}{}
+	if err := json.Unmarshal(b, &common); err != nil {
+		return err
+	}
+	settings := map[string]interface{}{}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		return err
+	}
+	for _, key := range []string{"type", "name", "is_enabled", "filter", "processors"} {
+		delete(settings, key)
+	}
+
+	p.Type = common.Type
+	p.Name = common.Name
+	p.IsEnabled = common.IsEnabled
+	p.Filter = common.Filter
+	p.Processors = common.Processors
+	p.Settings = settings
+	return nil
+}
+
+func (p LogsProcessor) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{}
+	for k, v := range p.Settings {
+		m[k] = v
+	}
+	m["type"] = p.Type
+	m["name"] = p.Name
+	m["is_enabled"] = p.IsEnabled
+	if p.Filter != nil {
+		m["filter"] = p.Filter
+	}
+	if p.Processors != nil {
+		m["processors"] = p.Processors
+	}
+	return json.Marshal(m)
+}
+
+type LogsIndex struct {
+	// Raw is the index as returned by the API.
+	Raw              json.RawMessage       `json:"-"`
+	Name             string                `json:"name"`
+	Filter           LogsFilter            `json:"filter"`
+	NumRetentionDays int                   `json:"num_retention_days"`
+	DailyLimit       *int64                `json:"daily_limit,omitempty"`
+	IsRateLimited    bool                  `json:"is_rate_limited"`
+	ExclusionFilters []LogsExclusionFilter `json:"exclusion_filters"`
+}
+
+type LogsExclusionFilter struct {
+	Name      string `json:"name"`
+	IsEnabled bool   `json:"is_enabled"`
+	Filter    struct {
+		Query      string  `json:"query"`
+		SampleRate float64 `json:"sample_rate"`
+	} `json:"filter"`
+}
+
+type LogsArchive struct {
+	// Raw is the archive as returned by the API.
+	Raw        json.RawMessage `json:"-"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Attributes struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+		// Destination depends on the storage type, e.g. s3, gcs or azure.
+		Destination                map[string]interface{} `json:"destination"`
+		RehydrationTags            []string               `json:"rehydration_tags"`
+		IncludeTags                bool                   `json:"include_tags"`
+		RehydrationMaxScanSizeInGB *int                   `json:"rehydration_max_scan_size_in_gb"`
+		State                      string                 `json:"state"`
+	} `json:"attributes"`
+}
+
+type LogsMetric struct {
+	// Raw is the log metric as returned by the API.
+	Raw        json.RawMessage `json:"-"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Attributes struct {
+		Compute struct {
+			AggregationType string `json:"aggregation_type"`
+			Path            string `json:"path,omitempty"`
+		} `json:"compute"`
+		Filter  LogsFilter `json:"filter"`
+		GroupBy []struct {
+			Path    string `json:"path"`
+			TagName string `json:"tag_name"`
+		} `json:"group_by"`
+	} `json:"attributes"`
+}
+
+func (p *LogsPipeline) UnmarshalJSON(b []byte) error {
+	type plain LogsPipeline
+	if err := json.Unmarshal(b, (*plain)(p)); err != nil {
+		return err
+	}
+	p.Raw = append(json.RawMessage{}, b...)
+	return nil
+}
+
+func (i *LogsIndex) UnmarshalJSON(b []byte) error {
+	type plain LogsIndex
+	if err := json.Unmarshal(b, (*plain)(i)); err != nil {
+		return err
+	}
+	i.Raw = append(json.RawMessage{}, b...)
+	return nil
+}
+
+func (a *LogsArchive) UnmarshalJSON(b []byte) error {
+	type plain LogsArchive
+	if err := json.Unmarshal(b, (*plain)(a)); err != nil {
+		return err
+	}
+	a.Raw = append(json.RawMessage{}, b...)
+	return nil
+}
+
+func (m *LogsMetric) UnmarshalJSON(b []byte) error {
+	type plain LogsMetric
+	if err := json.Unmarshal(b, (*plain)(m)); err != nil {
+		return err
+	}
+	m.Raw = append(json.RawMessage{}, b...)
+	return nil
+}
+
+func (d API) GetLogsPipelines() ([]LogsPipeline, error) {
+	pipelines := []LogsPipeline{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/logs/config/pipelines", nil, nil, &pipelines); err != nil {
+		return nil, err
+	}
+	return pipelines, nil
+}
+
+func (d API) GetLogsIndexes() ([]LogsIndex, error) {
+	resp := struct {
+		Indexes []LogsIndex `json:"indexes"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/logs/config/indexes", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Indexes, nil
+}
+
+func (d API) GetLogsArchives() ([]LogsArchive, error) {
+	resp := struct {
+		Data []LogsArchive `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v2/logs/config/archives", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+func (d API) GetLogsMetrics() ([]LogsMetric, error) {
+	resp := struct {
+		Data []LogsMetric `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v2/logs/config/metrics", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
@@ -0,0 +1,221 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLogsPipelines(t *testing.T) {
+	requestCount := 0
+	body := `[
+		{
+			"id": "abc123",
+			"type": "pipeline",
+			"name": "API",
+			"is_enabled": true,
+			"is_read_only": false,
+			"filter": {"query": "service:api"},
+			"processors": [
+				{
+					"type": "grok-parser",
+					"name": "Parse access log",
+					"is_enabled": true,
+					"source": "message",
+					"samples": [],
+					"grok": {"support_rules": "", "match_rules": "rule %{data:msg}"}
+				},
+				{
+					"type": "pipeline",
+					"name": "Nested",
+					"is_enabled": false,
+					"filter": {"query": "env:prod"},
+					"processors": [
+						{"type": "status-remapper", "name": "Status", "is_enabled": true, "sources": ["level"]}
+					]
+				}
+			]
+		}
+	]`
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/logs/config/pipelines", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	pipelines, err := api.GetLogsPipelines()
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, pipelines, 1)
+	require.Equal(t, "API", pipelines[0].Name)
+	require.Len(t, pipelines[0].Processors, 2)
+
+	grok := pipelines[0].Processors[0]
+	require.Equal(t, "grok-parser", grok.Type)
+	require.Equal(t, "message", grok.Settings["source"])
+	require.Nil(t, grok.Filter)
+
+	nested := pipelines[0].Processors[1]
+	require.Equal(t, "env:prod", nested.Filter.Query)
+	require.Equal(t, "status-remapper", nested.Processors[0].Type)
+	require.Equal(t, []interface{}{"level"}, nested.Processors[0].Settings["sources"])
+
+	// processors round trip without losing their settings
+	b, err := json.Marshal(pipelines)
+	require.NoError(t, err)
+	require.JSONEq(t, body, string(b))
+}
+
+func TestGetLogsIndexes(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/logs/config/indexes", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"indexes": [
+				{
+					"name": "main",
+					"filter": {"query": "*"},
+					"num_retention_days": 15,
+					"daily_limit": 300000000,
+					"is_rate_limited": false,
+					"exclusion_filters": [
+						{"name": "Drop debug", "is_enabled": true, "filter": {"query": "status:debug", "sample_rate": 1.0}}
+					]
+				}
+			]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	indexes, err := api.GetLogsIndexes()
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, indexes, 1)
+	require.Equal(t, "main", indexes[0].Name)
+	require.Equal(t, 15, indexes[0].NumRetentionDays)
+	require.Equal(t, int64(300000000), *indexes[0].DailyLimit)
+	require.Len(t, indexes[0].ExclusionFilters, 1)
+	require.Equal(t, "status:debug", indexes[0].ExclusionFilters[0].Filter.Query)
+	require.Equal(t, 1.0, indexes[0].ExclusionFilters[0].Filter.SampleRate)
+}
+
+func TestGetLogsArchives(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v2/logs/config/archives", r.URL.Path)
+		require.Equal(t, "api-key", r.Header.Get("DD-API-KEY"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"data": [
+				{
+					"id": "a2zcMylnM4OCHpYusxIi3g",
+					"type": "archives",
+					"attributes": {
+						"name": "Everything",
+						"query": "*",
+						"destination": {"type": "s3", "bucket": "logs-archive", "path": "/dd"},
+						"rehydration_tags": ["team:ops"],
+						"include_tags": true,
+						"state": "WORKING"
+					}
+				}
+			]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	archives, err := api.GetLogsArchives()
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, archives, 1)
+	require.Equal(t, "a2zcMylnM4OCHpYusxIi3g", archives[0].ID)
+	require.Equal(t, "Everything", archives[0].Attributes.Name)
+	require.Equal(t, "logs-archive", archives[0].Attributes.Destination["bucket"])
+	require.Equal(t, "WORKING", archives[0].Attributes.State)
+}
+
+func TestGetLogsMetrics(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v2/logs/config/metrics", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"data": [
+				{
+					"id": "api.errors",
+					"type": "logs_metrics",
+					"attributes": {
+						"compute": {"aggregation_type": "distribution", "path": "@duration", "include_percentiles": true},
+						"filter": {"query": "service:api status:error"},
+						"group_by": [{"path": "@http.status_code", "tag_name": "status_code"}]
+					}
+				}
+			]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	metrics, err := api.GetLogsMetrics()
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, metrics, 1)
+	require.Equal(t, "api.errors", metrics[0].ID)
+	require.Equal(t, "distribution", metrics[0].Attributes.Compute.AggregationType)
+	require.Equal(t, "service:api status:error", metrics[0].Attributes.Filter.Query)
+	require.Equal(t, "status_code", metrics[0].Attributes.GroupBy[0].TagName)
+
+	// fields that aren't modelled are kept for exporting
+	require.JSONEq(t, `{
+		"id": "api.errors",
+		"type": "logs_metrics",
+		"attributes": {
+			"compute": {"aggregation_type": "distribution", "path": "@duration", "include_percentiles": true},
+			"filter": {"query": "service:api status:error"},
+			"group_by": [{"path": "@http.status_code", "tag_name": "status_code"}]
+		}
+	}`, string(metrics[0].Raw))
+}
@@ -1,5 +1,13 @@
 package datadog
 
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
 type metricsResponse struct {
 	Metrics []string `json:"metrics"`
 	From    string   `json:"from"`
@@ -15,3 +23,145 @@ type MetricsUsage struct {
 type metricsUsageResponse struct {
 	Usage []MetricsUsage `json:"usage"`
 }
+
+// MetricMetadata describes a metric. Empty fields are left unchanged when
+// updating.
+type MetricMetadata struct {
+	Type           string `json:"type,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ShortName      string `json:"short_name,omitempty"`
+	Unit           string `json:"unit,omitempty"`
+	PerUnit        string `json:"per_unit,omitempty"`
+	StatsdInterval int    `json:"statsd_interval,omitempty"`
+	Integration    string `json:"integration,omitempty"`
+}
+
+func (d API) GetMetricMetadata(name string) (*MetricMetadata, error) {
+	metadata := new(MetricMetadata)
+	if err := d.doJSON(http.MethodGet, "/api/v1/metrics/"+url.PathEscape(name), nil, nil, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func (d API) UpdateMetricMetadata(name string, metadata MetricMetadata) (*MetricMetadata, error) {
+	// integration is read only
+	metadata.Integration = ""
+	updated := new(MetricMetadata)
+	if err := d.doJSON(http.MethodPut, "/api/v1/metrics/"+url.PathEscape(name), nil, metadata, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Series is a timeseries returned by a metric query.
+type Series struct {
+	Metric      string  `json:"metric"`
+	DisplayName string  `json:"display_name"`
+	Scope       string  `json:"scope"`
+	Expression  string  `json:"expression"`
+	Interval    int     `json:"interval"`
+	Length      int     `json:"length"`
+	Start       int64   `json:"start"`
+	End         int64   `json:"end"`
+	Points      []Point `json:"pointlist"`
+}
+
+// Point is a timestamp in milliseconds since the epoch and a value, which is
+// nil if there is no data for that time.
+type Point [2]*float64
+
+// Time returns the point's timestamp.
+func (p Point) Time() time.Time {
+	if p[0] == nil {
+		return time.Time{}
+	}
+	ms := int64(*p[0])
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
+// Value returns the point's value, and false if there is no data for it.
+func (p Point) Value() (float64, bool) {
+	if p[1] == nil {
+		return 0, false
+	}
+	return *p[1], true
+}
+
+type queryResponse struct {
+	Status string   `json:"status"`
+	Error  string   `json:"error"`
+	Series []Series `json:"series"`
+}
+
+// QueryMetrics returns the timeseries for a metric query between from and to.
+func (d API) QueryMetrics(q string, from time.Time, to time.Time) ([]Series, error) {
+	query := url.Values{}
+	query.Set("query", q)
+	query.Set("from", strconv.FormatInt(from.Unix(), 10))
+	query.Set("to", strconv.FormatInt(to.Unix(), 10))
+
+	var resp queryResponse
+	if err := d.doJSON(http.MethodGet, "/api/v1/query", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status == "error" {
+		return nil, errors.New("query failed: " + resp.Error)
+	}
+	return resp.Series, nil
+}
+
+// SubmittedSeries is a timeseries to submit, with points of Unix timestamps
+// in seconds and values.
+type SubmittedSeries struct {
+	Metric   string       `json:"metric"`
+	Points   [][2]float64 `json:"points"`
+	Type     string       `json:"type,omitempty"`
+	Interval int          `json:"interval,omitempty"`
+	Host     string       `json:"host,omitempty"`
+	Tags     []string     `json:"tags,omitempty"`
+}
+
+// PostSeries submits custom metric points.
+func (d API) PostSeries(series []SubmittedSeries) error {
+	req := struct {
+		Series []SubmittedSeries `json:"series"`
+	}{series}
+	return d.doJSON(http.MethodPost, "/api/v1/series", nil, req, nil)
+}
+
+// GetMetricTags returns every tag that has been submitted with a metric.
+func (d API) GetMetricTags(name string) ([]string, error) {
+	resp := struct {
+		Data struct {
+			Attributes struct {
+				Tags []string `json:"tags"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v2/metrics/"+url.PathEscape(name)+"/all-tags", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data.Attributes.Tags, nil
+}
+
+// MetricVolumes is the number of distinct timeseries for a metric. Indexed
+// and ingested volumes are set for standard metrics, distinct volume for
+// distributions.
+type MetricVolumes struct {
+	IndexedVolume  int64 `json:"indexed_volume"`
+	IngestedVolume int64 `json:"ingested_volume"`
+	DistinctVolume int64 `json:"distinct_volume"`
+}
+
+func (d API) GetMetricVolumes(name string) (*MetricVolumes, error) {
+	resp := struct {
+		Data struct {
+			Attributes MetricVolumes `json:"attributes"`
+		} `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v2/metrics/"+url.PathEscape(name)+"/volumes", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data.Attributes, nil
+}
@@ -0,0 +1,276 @@
+package datadog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMetricMetadata(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/metrics/system.net.bytes_sent", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"description": "My custom description",
+			"short_name": "bytes sent",
+			"integration": "system",
+			"statsd_interval": null,
+			"per_unit": "second",
+			"type": "gauge",
+			"unit": "byte"
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	metadata, err := api.GetMetricMetadata("system.net.bytes_sent")
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, &MetricMetadata{
+		Type:        "gauge",
+		Description: "My custom description",
+		ShortName:   "bytes sent",
+		Unit:        "byte",
+		PerUnit:     "second",
+		Integration: "system",
+	}, metadata)
+}
+
+func TestUpdateMetricMetadata(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "PUT", r.Method)
+		require.Equal(t, "/api/v1/metrics/myservice.requests", r.URL.Path)
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"type": "count", "unit": "request", "statsd_interval": 10}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"type": "count", "unit": "request", "statsd_interval": 10, "description": "Requests"}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	metadata, err := api.UpdateMetricMetadata("myservice.requests", MetricMetadata{
+		Type:           "count",
+		Unit:           "request",
+		StatsdInterval: 10,
+		Integration:    "ignored",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, "Requests", metadata.Description)
+}
+
+func TestQueryMetrics(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/query", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+		require.Equal(t, "avg:system.cpu.idle{*} by {host}", r.URL.Query().Get("query"))
+		require.Equal(t, "1545717600", r.URL.Query().Get("from"))
+		require.Equal(t, "1545721200", r.URL.Query().Get("to"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"status": "ok",
+			"res_type": "time_series",
+			"series": [
+				{
+					"metric": "system.cpu.idle",
+					"display_name": "system.cpu.idle",
+					"scope": "host:web-1",
+					"expression": "avg:system.cpu.idle{host:web-1}",
+					"interval": 20,
+					"length": 2,
+					"start": 1545717600000,
+					"end": 1545717639000,
+					"pointlist": [[1545717600000.0, 98.5], [1545717620000.0, null]]
+				}
+			],
+			"query": "avg:system.cpu.idle{*} by {host}"
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	series, err := api.QueryMetrics("avg:system.cpu.idle{*} by {host}", time.Unix(1545717600, 0), time.Unix(1545721200, 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, series, 1)
+	require.Equal(t, "host:web-1", series[0].Scope)
+	require.Len(t, series[0].Points, 2)
+
+	require.Equal(t, int64(1545717600), series[0].Points[0].Time().Unix())
+	v, ok := series[0].Points[0].Value()
+	require.True(t, ok)
+	require.Equal(t, 98.5, v)
+
+	require.Equal(t, int64(1545717620), series[0].Points[1].Time().Unix())
+	_, ok = series[0].Points[1].Value()
+	require.False(t, ok)
+}
+
+func TestQueryMetricsError(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "error", "error": "Rule 'bad' is invalid", "series": []}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	_, err := api.QueryMetrics("bad", time.Unix(0, 0), time.Unix(1, 0))
+	require.EqualError(t, err, "query failed: Rule 'bad' is invalid")
+}
+
+func TestPostSeries(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/api/v1/series", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"series": [
+				{
+					"metric": "deploy.count",
+					"points": [[1545717600, 1]],
+					"type": "count",
+					"tags": ["service:checkout", "env:prod"]
+				}
+			]
+		}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"status": "ok"}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	err := api.PostSeries([]SubmittedSeries{
+		{
+			Metric: "deploy.count",
+			Points: [][2]float64{{1545717600, 1}},
+			Type:   "count",
+			Tags:   []string{"service:checkout", "env:prod"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+}
+
+func TestGetMetricTags(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v2/metrics/myservice.requests/all-tags", r.URL.Path)
+		// v2 endpoints ignore keys in the query string
+		require.Equal(t, "api-key", r.Header.Get("DD-API-KEY"))
+		require.Equal(t, "app-key", r.Header.Get("DD-APPLICATION-KEY"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"data": {
+				"type": "metrics",
+				"id": "myservice.requests",
+				"attributes": {
+					"tags": ["env:prod", "env:staging", "host:web-1"]
+				}
+			}
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	tags, err := api.GetMetricTags("myservice.requests")
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, []string{"env:prod", "env:staging", "host:web-1"}, tags)
+}
+
+func TestGetMetricVolumes(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v2/metrics/myservice.requests/volumes", r.URL.Path)
+		require.Equal(t, "api-key", r.Header.Get("DD-API-KEY"))
+		require.Equal(t, "app-key", r.Header.Get("DD-APPLICATION-KEY"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"data": {
+				"type": "metric_volumes",
+				"id": "myservice.requests",
+				"attributes": {
+					"indexed_volume": 1200,
+					"ingested_volume": 4500
+				}
+			}
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	volumes, err := api.GetMetricVolumes("myservice.requests")
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Equal(t, &MetricVolumes{IndexedVolume: 1200, IngestedVolume: 4500}, volumes)
+}
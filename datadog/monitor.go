@@ -17,13 +17,8 @@ type Monitor struct {
 	Modified          time.Time     `json:"modified"`
 	OverallState      string        `json:"overall_state"`
 	Type              string        `json:"type"`
-	Creator           struct {
-		ID     int    `json:"id"`
-		Handle string `json:"handle"`
-		Name   string `json:"name"`
-		Email  string `json:"email"`
-	} `json:"creator"`
-	Options struct {
+	Creator           Creator       `json:"creator"`
+	Options           struct {
 		NotifyAudit bool `json:"notify_audit"`
 		Locked      bool `json:"locked"`
 		TimeoutH    int  `json:"timeout_h"`
@@ -37,4 +32,44 @@ type Monitor struct {
 		RenotifyInterval  int  `json:"renotify_interval"`
 		NoDataTimeframe   int  `json:"no_data_timeframe"`
 	} `json:"options"`
+	State *MonitorState `json:"state,omitempty"`
+}
+
+// MonitorState holds the status of each group of a multi alert monitor, e.g.
+// per host. It is only returned by GetMonitor.
+type MonitorState struct {
+	Groups map[string]MonitorGroupState `json:"groups,omitempty"`
+}
+
+type MonitorGroupState struct {
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	LastTriggeredTS int64  `json:"last_triggered_ts"`
+	LastNotifiedTS  int64  `json:"last_notified_ts"`
+	LastResolvedTS  int64  `json:"last_resolved_ts"`
+	LastNoDataTS    int64  `json:"last_nodata_ts"`
+	TriggeringValue *struct {
+		Value float64 `json:"value"`
+	} `json:"triggering_value,omitempty"`
+}
+
+// MonitorDefinition holds the fields of a new monitor. Options are kept as
+// given, as they depend on the monitor type.
+type MonitorDefinition struct {
+	Name    string                 `json:"name"`
+	Type    string                 `json:"type"`
+	Query   string                 `json:"query"`
+	Message string                 `json:"message"`
+	Tags    []string               `json:"tags"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// MonitorUpdate holds the fields to change when updating a monitor. Nil fields
+// are left unchanged.
+type MonitorUpdate struct {
+	Name    *string                 `json:"name,omitempty"`
+	Query   *string                 `json:"query,omitempty"`
+	Message *string                 `json:"message,omitempty"`
+	Tags    *[]string               `json:"tags,omitempty"`
+	Options *map[string]interface{} `json:"options,omitempty"`
 }
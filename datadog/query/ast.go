@@ -0,0 +1,301 @@
+package query
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Expr is a node in a parsed metric query.
+type Expr interface {
+	// String renders the expression back into Datadog query syntax.
+	String() string
+}
+
+// MonitorQuery is a metric monitor query, such as
+// `avg(last_5m):avg:system.cpu.user{env:prod} by {host} > 90`.
+type MonitorQuery struct {
+	// Aggregation is the time aggregation, e.g. avg, sum, change.
+	Aggregation string
+	// Window is the text inside the aggregation's parentheses, usually a
+	// time window like last_5m. For change and pct_change this includes the
+	// nested aggregation, e.g. avg(last_5m),last_5m.
+	Window     string
+	Query      Expr
+	Comparator string
+	Threshold  float64
+}
+
+func (m *MonitorQuery) String() string {
+	return m.Aggregation + "(" + m.Window + "):" + m.Query.String() + " " + m.Comparator + " " + formatNumber(m.Threshold)
+}
+
+func (m *MonitorQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node        string  `json:"node"`
+		Aggregation string  `json:"aggregation"`
+		Window      string  `json:"window"`
+		Query       Expr    `json:"query"`
+		Comparator  string  `json:"comparator"`
+		Threshold   float64 `json:"threshold"`
+	}{"monitor", m.Aggregation, m.Window, m.Query, m.Comparator, m.Threshold})
+}
+
+// Metric is a single metric query, such as
+// `avg:system.cpu.user{env:prod,role:db} by {host}.as_count()`.
+type Metric struct {
+	// Aggregator is the space aggregator, e.g. avg, sum, min or max. It may be
+	// empty.
+	Aggregator string
+	Name       string
+	// Scope holds the tags from the {} after the metric name, "*" for all.
+	Scope     []string
+	GroupBy   []string
+	Modifiers []Modifier
+	// GroupByLast is set when by {...} followed the modifiers, as in
+	// `sum:a{*}.rollup(sum, 60) by {host}`, so that it is rendered the same way.
+	GroupByLast bool
+}
+
+func (m *Metric) String() string {
+	s := ""
+	if m.Aggregator != "" {
+		s = m.Aggregator + ":"
+	}
+	s += m.Name + "{" + strings.Join(m.Scope, ",") + "}"
+	groupBy := ""
+	if len(m.GroupBy) > 0 {
+		groupBy = " by {" + strings.Join(m.GroupBy, ",") + "}"
+	}
+	if !m.GroupByLast {
+		s += groupBy
+	}
+	for _, mod := range m.Modifiers {
+		s += "." + mod.Name + "(" + strings.Join(mod.Args, ",") + ")"
+	}
+	if m.GroupByLast {
+		s += groupBy
+	}
+	return s
+}
+
+func (m *Metric) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node       string     `json:"node"`
+		Aggregator string     `json:"aggregator,omitempty"`
+		Name       string     `json:"name"`
+		Scope      []string   `json:"scope"`
+		GroupBy    []string   `json:"group_by,omitempty"`
+		Modifiers  []Modifier `json:"modifiers,omitempty"`
+	}{"metric", m.Aggregator, m.Name, m.Scope, m.GroupBy, m.Modifiers})
+}
+
+// Modifier is a method applied to a metric query, such as .as_count() or
+// .rollup(sum, 60).
+type Modifier struct {
+	Name string   `json:"name"`
+	Args []string `json:"args,omitempty"`
+}
+
+// Function is a function applied to queries, such as per_second(...) or
+// top(..., 10, 'mean', 'desc').
+type Function struct {
+	Name string
+	Args []Expr
+}
+
+func (f *Function) String() string {
+	args := make([]string, len(f.Args))
+	for i, arg := range f.Args {
+		args[i] = arg.String()
+	}
+	return f.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (f *Function) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node string `json:"node"`
+		Name string `json:"name"`
+		Args []Expr `json:"args"`
+	}{"function", f.Name, f.Args})
+}
+
+// Keyword is a named function argument, such as direction='above' in
+// anomalies(avg:a{*}, 'basic', 2, direction='above').
+type Keyword struct {
+	Name  string
+	Value Expr
+}
+
+func (k *Keyword) String() string {
+	return k.Name + "=" + k.Value.String()
+}
+
+func (k *Keyword) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node  string `json:"node"`
+		Name  string `json:"name"`
+		Value Expr   `json:"value"`
+	}{"keyword", k.Name, k.Value})
+}
+
+// Binary is an arithmetic expression between two queries, such as a / b * 100.
+type Binary struct {
+	Op          string
+	Left, Right Expr
+}
+
+func (b *Binary) String() string {
+	return b.Left.String() + " " + b.Op + " " + b.Right.String()
+}
+
+func (b *Binary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node  string `json:"node"`
+		Op    string `json:"op"`
+		Left  Expr   `json:"left"`
+		Right Expr   `json:"right"`
+	}{"binary", b.Op, b.Left, b.Right})
+}
+
+// Paren is a parenthesised expression.
+type Paren struct {
+	Expr Expr
+}
+
+func (p *Paren) String() string {
+	return "(" + p.Expr.String() + ")"
+}
+
+func (p *Paren) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node string `json:"node"`
+		Expr Expr   `json:"expr"`
+	}{"paren", p.Expr})
+}
+
+// List is a comma separated list of queries drawn as separate series in one
+// graph request, such as `avg:a{*}, avg:b{*}`.
+type List struct {
+	Exprs []Expr
+}
+
+func (l *List) String() string {
+	exprs := make([]string, len(l.Exprs))
+	for i, expr := range l.Exprs {
+		exprs[i] = expr.String()
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func (l *List) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node  string `json:"node"`
+		Exprs []Expr `json:"exprs"`
+	}{"list", l.Exprs})
+}
+
+// Number is a numeric literal.
+type Number struct {
+	Value float64
+}
+
+func (n *Number) String() string {
+	return formatNumber(n.Value)
+}
+
+func (n *Number) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node  string  `json:"node"`
+		Value float64 `json:"value"`
+	}{"number", n.Value})
+}
+
+// Literal is a bare word or quoted string argument to a function, such as
+// mean or 'desc'. Quotes are kept in Value.
+type Literal struct {
+	Value string
+}
+
+func (l *Literal) String() string {
+	return l.Value
+}
+
+func (l *Literal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Node  string `json:"node"`
+		Value string `json:"value"`
+	}{"literal", l.Value})
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Walk calls fn for expr and each expression nested inside it, depth first.
+func Walk(expr Expr, fn func(Expr)) {
+	fn(expr)
+	switch e := expr.(type) {
+	case *MonitorQuery:
+		Walk(e.Query, fn)
+	case *Function:
+		for _, arg := range e.Args {
+			Walk(arg, fn)
+		}
+	case *Binary:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *Keyword:
+		Walk(e.Value, fn)
+	case *Paren:
+		Walk(e.Expr, fn)
+	case *List:
+		for _, expr := range e.Exprs {
+			Walk(expr, fn)
+		}
+	}
+}
+
+// Metrics returns every metric query within expr.
+func Metrics(expr Expr) []*Metric {
+	metrics := []*Metric{}
+	Walk(expr, func(e Expr) {
+		if m, ok := e.(*Metric); ok {
+			metrics = append(metrics, m)
+		}
+	})
+	return metrics
+}
+
+// RenameMetric renames every query of metric old within expr to new,
+// returning whether any were renamed.
+func RenameMetric(expr Expr, old string, new string) bool {
+	renamed := false
+	for _, m := range Metrics(expr) {
+		if m.Name == old {
+			m.Name = new
+			renamed = true
+		}
+	}
+	return renamed
+}
+
+// RenameTag replaces the tag old with new in the scope of every metric query
+// within expr, including where it is excluded with !, returning whether any
+// were replaced.
+func RenameTag(expr Expr, old string, new string) bool {
+	renamed := false
+	for _, m := range Metrics(expr) {
+		for i, tag := range m.Scope {
+			switch tag {
+			case old:
+				m.Scope[i] = new
+				renamed = true
+			case "!" + old:
+				m.Scope[i] = "!" + new
+				renamed = true
+			}
+		}
+	}
+	return renamed
+}
@@ -0,0 +1,487 @@
+// Package query parses Datadog metric queries, as used in dashboard graph
+// requests, and metric monitor queries into an AST that can be inspected and
+// rewritten.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// monitorPrefix matches the time aggregation at the start of a monitor query,
+// e.g. avg(last_5m): or change(avg(last_5m),last_5m):
+var monitorPrefix = regexp.MustCompile(`^\s*([a-z_]+)\(((?:[^()]|\([^()]*\))*)\)\s*:`)
+
+var comparators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// SyntaxError describes where parsing a query failed.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// Parse parses either a monitor query or a metric query, depending on whether
+// s starts with a time aggregation like avg(last_5m):
+func Parse(s string) (Expr, error) {
+	return (&parser{s: s}).parse()
+}
+
+// Rewrite parses q and calls rewrite on it, such as to rename a metric with
+// RenameMetric, returning whether rewrite changed anything. The metric names
+// and scope tags that were changed are replaced in q, keeping the rest of it
+// as written rather than reformatted by String.
+func Rewrite(q string, rewrite func(Expr) bool) (string, bool, error) {
+	p := &parser{s: q}
+	expr, err := p.parse()
+	if err != nil {
+		return q, false, err
+	}
+	if !rewrite(expr) {
+		return q, false, nil
+	}
+
+	out := ""
+	last := 0
+	splice := func(span [2]int, text string) {
+		out += q[last:span[0]] + text
+		last = span[1]
+	}
+	for _, src := range p.metrics {
+		m := src.metric
+		if m.Name != src.name {
+			splice(src.nameSpan, m.Name)
+		}
+		if len(m.Scope) != len(src.scope) {
+			splice(src.scopeSpan, strings.Join(m.Scope, ","))
+			continue
+		}
+		for i, tag := range m.Scope {
+			if tag != src.scope[i] {
+				splice(src.scopeSpans[i], tag)
+			}
+		}
+	}
+	out += q[last:]
+
+	// anything else rewrite changed can only be rendered as a whole
+	if check, err := Parse(out); err != nil || check.String() != expr.String() {
+		return expr.String(), true, nil
+	}
+	return out, true, nil
+}
+
+func (p *parser) parse() (Expr, error) {
+	if monitorPrefix.MatchString(p.s) {
+		m, err := p.parseMonitor()
+		if err != nil {
+			return nil, err
+		}
+		return m, nil
+	}
+	return p.parseMetricQuery()
+}
+
+// ParseMonitor parses a metric monitor query such as
+// `avg(last_5m):avg:system.cpu.user{env:prod} by {host} > 90`.
+func ParseMonitor(s string) (*MonitorQuery, error) {
+	return (&parser{s: s}).parseMonitor()
+}
+
+func (p *parser) parseMonitor() (*MonitorQuery, error) {
+	s := p.s
+	match := monitorPrefix.FindStringSubmatchIndex(s)
+	if match == nil {
+		return nil, &SyntaxError{0, "expected time aggregation like avg(last_5m):"}
+	}
+	m := &MonitorQuery{
+		Aggregation: s[match[2]:match[3]],
+		Window:      strings.Replace(s[match[4]:match[5]], " ", "", -1),
+	}
+
+	p.pos = match[1]
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	m.Query = expr
+
+	p.skipSpace()
+	for _, c := range comparators {
+		if strings.HasPrefix(s[p.pos:], c) {
+			m.Comparator = c
+			p.pos += len(c)
+			break
+		}
+	}
+	if m.Comparator == "" {
+		return nil, p.errorf("expected comparator")
+	}
+	p.skipSpace()
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	p.scanNumber()
+	threshold, err := strconv.ParseFloat(s[start:p.pos], 64)
+	if err != nil {
+		return nil, &SyntaxError{start, "expected threshold"}
+	}
+	m.Threshold = threshold
+
+	p.skipSpace()
+	if p.pos < len(s) {
+		return nil, p.errorf("unexpected %q", s[p.pos:])
+	}
+	return m, nil
+}
+
+// ParseMetricQuery parses a metric query such as
+// `per_second(sum:nginx.requests{env:prod} by {host}.as_count())`, or a comma
+// separated list of them, which is returned as a *List.
+func ParseMetricQuery(s string) (Expr, error) {
+	return (&parser{s: s}).parseMetricQuery()
+}
+
+func (p *parser) parseMetricQuery() (Expr, error) {
+	s := p.s
+	exprs := []Expr{}
+	for {
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		p.skipSpace()
+		if p.peek() != ',' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos < len(s) {
+		return nil, p.errorf("unexpected %q", s[p.pos:])
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return &List{exprs}, nil
+}
+
+type parser struct {
+	s   string
+	pos int
+	// metrics records where each metric query was parsed from, for Rewrite
+	metrics []metricSource
+}
+
+// metricSource is where a metric query's name and scope tags were parsed
+// from, along with their values as parsed.
+type metricSource struct {
+	metric     *Metric
+	name       string
+	nameSpan   [2]int
+	scope      []string
+	scopeSpan  [2]int
+	scopeSpans [][2]int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{p.pos, fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n') {
+		p.pos++
+	}
+}
+
+func (p *parser) expect(c byte) error {
+	p.skipSpace()
+	if p.peek() != c {
+		if p.pos >= len(p.s) {
+			return p.errorf("expected %q, got end of query", c)
+		}
+		return p.errorf("expected %q, got %q", c, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+func isIdentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.'
+}
+
+func (p *parser) scanIdent() string {
+	start := p.pos
+	for p.pos < len(p.s) && isIdentChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// scanNumber scans a number such as 90, 0.5 or 1e-5, without its sign.
+func (p *parser) scanNumber() {
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if (c == 'e' || c == 'E') && p.pos+1 < len(p.s) && (p.s[p.pos+1] == '-' || p.s[p.pos+1] == '+') {
+			p.pos += 2
+			continue
+		}
+		if !(c >= '0' && c <= '9' || c == '.' || c == 'e' || c == 'E') {
+			return
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseExpr() (Expr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: string(op), Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseTerm() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpace()
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: string(op), Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	p.skipSpace()
+	if p.peek() == '-' {
+		start := p.pos
+		p.pos++
+		if c := p.peek(); c >= '0' && c <= '9' {
+			p.scanNumber()
+			f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+			if err != nil {
+				return nil, &SyntaxError{start, "invalid number"}
+			}
+			return &Number{f}, nil
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Binary{Op: "*", Left: &Number{-1}, Right: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	p.skipSpace()
+	start := p.pos
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, p.errorf("unexpected end of query")
+	case c == '(':
+		p.pos++
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return &Paren{expr}, nil
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(p.s[p.pos+1:], c)
+		if end < 0 {
+			return nil, p.errorf("unterminated string")
+		}
+		p.pos += end + 2
+		return &Literal{p.s[start:p.pos]}, nil
+	case c >= '0' && c <= '9':
+		p.scanNumber()
+		f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, &SyntaxError{start, "invalid number"}
+		}
+		return &Number{f}, nil
+	case !isIdentChar(c):
+		return nil, p.errorf("unexpected %q", c)
+	}
+
+	ident := p.scanIdent()
+	switch p.peek() {
+	case ':':
+		p.pos++
+		nameStart := p.pos
+		name := p.scanIdent()
+		if name == "" {
+			return nil, p.errorf("expected metric name")
+		}
+		return p.parseMetric(ident, name, nameStart)
+	case '{':
+		return p.parseMetric("", ident, start)
+	case '(':
+		p.pos++
+		f := &Function{Name: ident}
+		p.skipSpace()
+		if p.peek() == ')' {
+			p.pos++
+			return f, nil
+		}
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			f.Args = append(f.Args, arg)
+			p.skipSpace()
+			if p.peek() == ',' {
+				p.pos++
+				continue
+			}
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+			return f, nil
+		}
+	}
+
+	// keyword arguments, e.g. direction='above' in anomalies(...)
+	save := p.pos
+	p.skipSpace()
+	if p.peek() == '=' && !strings.HasPrefix(p.s[p.pos:], "==") {
+		p.pos++
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &Keyword{Name: ident, Value: value}, nil
+	}
+	p.pos = save
+	return &Literal{ident}, nil
+}
+
+func (p *parser) parseMetric(aggregator string, name string, nameStart int) (Expr, error) {
+	m := &Metric{Aggregator: aggregator, Name: name}
+	src := metricSource{metric: m, name: name, nameSpan: [2]int{nameStart, nameStart + len(name)}}
+	scope, spans, err := p.parseBraces()
+	if err != nil {
+		return nil, err
+	}
+	m.Scope = scope
+	src.scope = append([]string{}, scope...)
+	src.scopeSpans = spans
+	src.scopeSpan = [2]int{spans[0][0], spans[len(spans)-1][1]}
+	p.metrics = append(p.metrics, src)
+	if m.GroupBy, err = p.parseGroupBy(); err != nil {
+		return nil, err
+	}
+
+	for p.peek() == '.' {
+		p.pos++
+		mod := Modifier{Name: p.scanIdent()}
+		if mod.Name == "" {
+			return nil, p.errorf("expected modifier name")
+		}
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		end := strings.IndexByte(p.s[p.pos:], ')')
+		if end < 0 {
+			return nil, p.errorf("expected %q", ')')
+		}
+		for _, arg := range strings.Split(p.s[p.pos:p.pos+end], ",") {
+			if arg = strings.TrimSpace(arg); arg != "" {
+				mod.Args = append(mod.Args, arg)
+			}
+		}
+		p.pos += end + 1
+		m.Modifiers = append(m.Modifiers, mod)
+	}
+
+	// Datadog also accepts "by {...}" after the modifiers
+	if m.GroupBy == nil && len(m.Modifiers) > 0 {
+		if m.GroupBy, err = p.parseGroupBy(); err != nil {
+			return nil, err
+		}
+		m.GroupByLast = m.GroupBy != nil
+	}
+	return m, nil
+}
+
+// parseGroupBy parses "by {...}" if it follows, possibly after whitespace,
+// returning nil if it doesn't.
+func (p *parser) parseGroupBy() ([]string, error) {
+	save := p.pos
+	p.skipSpace()
+	if !strings.HasPrefix(p.s[p.pos:], "by") {
+		p.pos = save
+		return nil, nil
+	}
+	p.pos += 2
+	p.skipSpace()
+	items, _, err := p.parseBraces()
+	return items, err
+}
+
+// parseBraces parses a comma separated list in braces, e.g. {env:prod,host:a},
+// also returning where each item is in the query.
+func (p *parser) parseBraces() ([]string, [][2]int, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, nil, err
+	}
+	end := strings.IndexByte(p.s[p.pos:], '}')
+	if end < 0 {
+		return nil, nil, p.errorf("expected %q", '}')
+	}
+	items := []string{}
+	spans := [][2]int{}
+	start := p.pos
+	for _, raw := range strings.Split(p.s[p.pos:p.pos+end], ",") {
+		item := strings.TrimSpace(raw)
+		if item == "" {
+			return nil, nil, p.errorf("empty item in braces")
+		}
+		itemStart := start + strings.Index(raw, item)
+		items = append(items, item)
+		spans = append(spans, [2]int{itemStart, itemStart + len(item)})
+		start += len(raw) + 1
+	}
+	p.pos += end + 1
+	return items, spans, nil
+}
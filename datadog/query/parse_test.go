@@ -0,0 +1,315 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMonitor(t *testing.T) {
+	m, err := ParseMonitor("avg(last_5m):avg:system.cpu.user{env:prod} by {host} > 90")
+	require.NoError(t, err)
+
+	expected := &MonitorQuery{
+		Aggregation: "avg",
+		Window:      "last_5m",
+		Query: &Metric{
+			Aggregator: "avg",
+			Name:       "system.cpu.user",
+			Scope:      []string{"env:prod"},
+			GroupBy:    []string{"host"},
+		},
+		Comparator: ">",
+		Threshold:  90,
+	}
+	require.Equal(t, expected, m)
+}
+
+func TestParseMetricQuery(t *testing.T) {
+	expr, err := ParseMetricQuery("top(per_second(sum:nginx.requests{env:prod,role:web} by {host,az}.as_count()), 10, 'mean', 'desc')")
+	require.NoError(t, err)
+
+	expected := &Function{
+		Name: "top",
+		Args: []Expr{
+			&Function{
+				Name: "per_second",
+				Args: []Expr{
+					&Metric{
+						Aggregator: "sum",
+						Name:       "nginx.requests",
+						Scope:      []string{"env:prod", "role:web"},
+						GroupBy:    []string{"host", "az"},
+						Modifiers:  []Modifier{{Name: "as_count"}},
+					},
+				},
+			},
+			&Number{10},
+			&Literal{"'mean'"},
+			&Literal{"'desc'"},
+		},
+	}
+	require.Equal(t, expected, expr)
+}
+
+func TestParseArithmetic(t *testing.T) {
+	expr, err := ParseMetricQuery("sum:errors{*}.rollup(sum, 60) / (sum:requests{*} + 1) * 100")
+	require.NoError(t, err)
+
+	expected := &Binary{
+		Op: "*",
+		Left: &Binary{
+			Op: "/",
+			Left: &Metric{
+				Aggregator: "sum",
+				Name:       "errors",
+				Scope:      []string{"*"},
+				Modifiers:  []Modifier{{Name: "rollup", Args: []string{"sum", "60"}}},
+			},
+			Right: &Paren{&Binary{
+				Op:    "+",
+				Left:  &Metric{Aggregator: "sum", Name: "requests", Scope: []string{"*"}},
+				Right: &Number{1},
+			}},
+		},
+		Right: &Number{100},
+	}
+	require.Equal(t, expected, expr)
+}
+
+func TestParseList(t *testing.T) {
+	expr, err := ParseMetricQuery("avg:a{*}, sum:b{env:prod}.rollup(sum, 60) by {host}")
+	require.NoError(t, err)
+
+	expected := &List{[]Expr{
+		&Metric{Aggregator: "avg", Name: "a", Scope: []string{"*"}},
+		&Metric{
+			Aggregator:  "sum",
+			Name:        "b",
+			Scope:       []string{"env:prod"},
+			GroupBy:     []string{"host"},
+			Modifiers:   []Modifier{{Name: "rollup", Args: []string{"sum", "60"}}},
+			GroupByLast: true,
+		},
+	}}
+	require.Equal(t, expected, expr)
+	require.Len(t, Metrics(expr), 2)
+}
+
+func TestParseKeywordsAndExponents(t *testing.T) {
+	m, err := ParseMonitor("avg(last_4h):anomalies(avg:system.cpu.user{env:prod}, 'basic', 2, direction='above', interval=60) >= 1e-5")
+	require.NoError(t, err)
+
+	expected := &MonitorQuery{
+		Aggregation: "avg",
+		Window:      "last_4h",
+		Query: &Function{
+			Name: "anomalies",
+			Args: []Expr{
+				&Metric{Aggregator: "avg", Name: "system.cpu.user", Scope: []string{"env:prod"}},
+				&Literal{"'basic'"},
+				&Number{2},
+				&Keyword{"direction", &Literal{"'above'"}},
+				&Keyword{"interval", &Number{60}},
+			},
+		},
+		Comparator: ">=",
+		Threshold:  1e-5,
+	}
+	require.Equal(t, expected, m)
+
+	expr, err := Parse("timeshift(avg:a{*}, -1.5E+3)")
+	require.NoError(t, err)
+	require.Equal(t, &Number{-1500}, expr.(*Function).Args[1])
+}
+
+func TestRoundTrip(t *testing.T) {
+	queries := []string{
+		"avg(last_5m):avg:system.cpu.user{env:prod} by {host} > 90",
+		"sum(last_1h):sum:app.errors{service:checkout}.as_count() >= 100",
+		"change(avg(last_5m),last_5m):avg:system.load.1{*} < -0.5",
+		"avg:system.cpu.user{*}",
+		"top(avg:system.cpu.user{*} by {host}, 10, 'mean', 'desc')",
+		"sum:errors{*}.rollup(sum,60) / (sum:requests{*} + 1) * 100",
+		"timeshift(avg:system.load.1{*}, -3600)",
+		"avg:system.cpu.user{*} by {host}, avg:system.cpu.system{*} by {host}",
+		"sum:app.requests{*}.rollup(sum,60) by {service}",
+		"percentile(last_5m):p99:app.latency{env:prod} > 1.5",
+		"avg(last_4h):anomalies(avg:system.cpu.user{*}, 'basic', 2, direction='above', alert_window='last_15m') >= 1",
+	}
+	for _, q := range queries {
+		expr, err := Parse(q)
+		require.NoError(t, err, q)
+		require.Equal(t, q, expr.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"avg:system.cpu.user", `expected '{', got end of query at position 19`},
+		{"avg:system.cpu.user{env:prod", `expected '}' at position 20`},
+		{"avg(last_5m):avg:system.cpu.user{*}", `expected comparator at position 35`},
+		{"avg(last_5m):avg:system.cpu.user{*} > high", `expected threshold at position 38`},
+		{"avg:a{*} avg:b{*}", `unexpected "avg:b{*}" at position 9`},
+		{"avg:a{*},", `unexpected end of query at position 9`},
+		{"avg(last_5m):avg:a{*}, avg:b{*} > 1", `expected comparator at position 21`},
+		{"top(avg:a{*}, 10", `expected ')', got end of query at position 16`},
+		{"", `unexpected end of query at position 0`},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		require.EqualError(t, err, test.expected, test.input)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	expr, err := Parse("avg(last_5m):sum:a.errors{*} / sum:a.requests{*} > 0.1")
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, m := range Metrics(expr) {
+		names = append(names, m.Name)
+	}
+	require.Equal(t, []string{"a.errors", "a.requests"}, names)
+
+	// rewriting a metric in place is reflected when rendering
+	Metrics(expr)[0].Name = "a.failures"
+	require.Equal(t, "avg(last_5m):sum:a.failures{*} / sum:a.requests{*} > 0.1", expr.String())
+}
+
+func TestValidate(t *testing.T) {
+	expr, err := Parse("median(last_5m):mean:_bad{*}.as_percent() > 1")
+	require.NoError(t, err)
+
+	errs := Validate(expr)
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	require.Equal(t, []string{
+		`unknown time aggregation "median"`,
+		`unknown aggregator "mean" for metric _bad`,
+		`invalid metric name "_bad"`,
+		`unknown modifier .as_percent() for metric _bad`,
+	}, messages)
+
+	expr, err = Parse("avg(last_5m):avg:system.cpu.user{env:prod} by {host} > 90")
+	require.NoError(t, err)
+	require.Empty(t, Validate(expr))
+
+	expr, err = Parse("percentile(last_5m):p99:app.latency{env:prod} > 1.5")
+	require.NoError(t, err)
+	require.Empty(t, Validate(expr))
+
+	expr, err = Parse("p99.9:app.latency{*}, p50:app.latency{*}")
+	require.NoError(t, err)
+	require.Empty(t, Validate(expr))
+}
+
+func TestMarshalJSON(t *testing.T) {
+	expr, err := Parse("avg(last_5m):avg:system.cpu.user{env:prod} by {host} > 90")
+	require.NoError(t, err)
+
+	b, err := json.Marshal(expr)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"node": "monitor",
+		"aggregation": "avg",
+		"window": "last_5m",
+		"query": {
+			"node": "metric",
+			"aggregator": "avg",
+			"name": "system.cpu.user",
+			"scope": ["env:prod"],
+			"group_by": ["host"]
+		},
+		"comparator": ">",
+		"threshold": 90
+	}`, string(b))
+}
+
+func TestRenameMetric(t *testing.T) {
+	expr, err := Parse("per_second(sum:app.req.count{env:prod}.as_count()) / sum:app.req.countx{*}")
+	require.NoError(t, err)
+	require.True(t, RenameMetric(expr, "app.req.count", "app.requests.total"))
+	require.Equal(t, "per_second(sum:app.requests.total{env:prod}.as_count()) / sum:app.req.countx{*}", expr.String())
+	require.False(t, RenameMetric(expr, "app.req.count", "app.requests.total"))
+}
+
+func TestRenameTag(t *testing.T) {
+	expr, err := Parse("avg(last_5m):avg:a.latency{service:old,!service:old-canary} by {service} - avg:a.latency{env:prod,!service:old} > 1")
+	require.NoError(t, err)
+	require.True(t, RenameTag(expr, "service:old", "service:new"))
+	require.Equal(t, "avg(last_5m):avg:a.latency{service:new,!service:old-canary} by {service} - avg:a.latency{env:prod,!service:new} > 1", expr.String())
+	require.False(t, RenameTag(expr, "service:old", "service:new"))
+}
+
+func TestRewrite(t *testing.T) {
+	renameTag := func(expr Expr) bool { return RenameTag(expr, "service:old", "service:new") }
+	renameMetric := func(expr Expr) bool { return RenameMetric(expr, "app.req.count", "app.requests.total") }
+	tests := []struct {
+		input    string
+		rewrite  func(Expr) bool
+		expected string
+		changed  bool
+	}{
+		{
+			"sum:errors{env:prod, service:old}.rollup(sum, 60) / sum:requests{!service:old}*100",
+			renameTag,
+			"sum:errors{env:prod, service:new}.rollup(sum, 60) / sum:requests{!service:new}*100",
+			true,
+		},
+		{
+			"top(avg:app.req.count{*} by {host},10,'mean','desc')",
+			renameMetric,
+			"top(avg:app.requests.total{*} by {host},10,'mean','desc')",
+			true,
+		},
+		{
+			"avg(last_4h):anomalies(avg:app.req.count{service:old}, 'basic', 2, direction='above') >= 1e-5",
+			func(expr Expr) bool { return renameMetric(expr) && renameTag(expr) },
+			"avg(last_4h):anomalies(avg:app.requests.total{service:new}, 'basic', 2, direction='above') >= 1e-5",
+			true,
+		},
+		{
+			"sum:app.req.count{service:other}.rollup(sum, 60)",
+			renameTag,
+			"sum:app.req.count{service:other}.rollup(sum, 60)",
+			false,
+		},
+		{
+			// changes other than names and tags are rendered with String
+			"sum:a{x, y}.rollup(sum, 60)",
+			func(expr Expr) bool {
+				m := Metrics(expr)[0]
+				m.Modifiers = append(m.Modifiers, Modifier{Name: "as_count"})
+				return true
+			},
+			"sum:a{x,y}.rollup(sum,60).as_count()",
+			true,
+		},
+		{
+			"sum:a{x, y}",
+			func(expr Expr) bool {
+				m := Metrics(expr)[0]
+				m.Scope = m.Scope[:1]
+				return true
+			},
+			"sum:a{x}",
+			true,
+		},
+	}
+	for _, test := range tests {
+		q, changed, err := Rewrite(test.input, test.rewrite)
+		require.NoError(t, err, test.input)
+		require.Equal(t, test.expected, q, test.input)
+		require.Equal(t, test.changed, changed, test.input)
+	}
+
+	_, _, err := Rewrite("sum:a{x", renameTag)
+	require.EqualError(t, err, `expected '}' at position 6`)
+}
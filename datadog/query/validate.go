@@ -0,0 +1,47 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var (
+	spaceAggregators = map[string]bool{"avg": true, "sum": true, "min": true, "max": true}
+	timeAggregations = map[string]bool{"avg": true, "sum": true, "min": true, "max": true, "last": true, "change": true, "pct_change": true, "percentile": true}
+	modifiers        = map[string]bool{"as_count": true, "as_rate": true, "rollup": true, "fill": true}
+
+	// percentile aggregators of distribution metrics, e.g. p99 or p99.9
+	percentileRegexp = regexp.MustCompile(`^p[0-9]{1,2}(\.[0-9]+)?$`)
+	windowRegexp     = regexp.MustCompile(`^last_[0-9]+[mhdw]$`)
+	metricNameRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]*$`)
+)
+
+// Validate checks a parsed query for mistakes that Datadog would reject, such
+// as unknown aggregators or malformed metric names. It returns every problem
+// found rather than stopping at the first.
+func Validate(expr Expr) []error {
+	var errs []error
+	Walk(expr, func(e Expr) {
+		switch e := e.(type) {
+		case *MonitorQuery:
+			if !timeAggregations[e.Aggregation] {
+				errs = append(errs, fmt.Errorf("unknown time aggregation %q", e.Aggregation))
+			} else if e.Aggregation != "change" && e.Aggregation != "pct_change" && !windowRegexp.MatchString(e.Window) {
+				errs = append(errs, fmt.Errorf("invalid time window %q, expected e.g. last_5m", e.Window))
+			}
+		case *Metric:
+			if e.Aggregator != "" && !spaceAggregators[e.Aggregator] && !percentileRegexp.MatchString(e.Aggregator) {
+				errs = append(errs, fmt.Errorf("unknown aggregator %q for metric %s", e.Aggregator, e.Name))
+			}
+			if !metricNameRegexp.MatchString(e.Name) || len(e.Name) > 200 {
+				errs = append(errs, fmt.Errorf("invalid metric name %q", e.Name))
+			}
+			for _, mod := range e.Modifiers {
+				if !modifiers[mod.Name] {
+					errs = append(errs, fmt.Errorf("unknown modifier .%s() for metric %s", mod.Name, e.Name))
+				}
+			}
+		}
+	})
+	return errs
+}
@@ -3,12 +3,13 @@ package datadog
 import "time"
 
 type ScreenboardSummary struct {
-	ID       int       `json:"id"`
-	ReadOnly bool      `json:"read_only"`
-	Resource string    `json:"resource"`
-	Created  time.Time `json:"created"`
-	Title    string    `json:"title"`
-	Modified time.Time `json:"modified"`
+	ID        int       `json:"id"`
+	ReadOnly  bool      `json:"read_only"`
+	Resource  string    `json:"resource"`
+	Created   time.Time `json:"created"`
+	Title     string    `json:"title"`
+	Modified  time.Time `json:"modified"`
+	CreatedBy *Creator  `json:"created_by,omitempty"`
 }
 
 type Screenboard struct {
@@ -52,3 +53,27 @@ type Screenboard struct {
 		Legend     bool   `json:"legend"`
 	} `json:"widgets"`
 }
+
+// ScreenboardDefinition holds the fields of a screenboard that can be set when
+// creating or updating it. Widgets and template variables are kept as given, as
+// widget definitions vary by type.
+type ScreenboardDefinition struct {
+	BoardTitle        string                   `json:"board_title"`
+	Description       string                   `json:"description,omitempty"`
+	ReadOnly          bool                     `json:"read_only,omitempty"`
+	Width             interface{}              `json:"width,omitempty"`
+	Height            interface{}              `json:"height,omitempty"`
+	Widgets           []map[string]interface{} `json:"widgets"`
+	TemplateVariables []map[string]interface{} `json:"template_variables,omitempty"`
+}
+
+// Requests returns the requests of every widget on the screenboard. Widgets
+// such as notes and images have none.
+func (s ScreenboardDefinition) Requests() []GraphRequest {
+	requests := []GraphRequest{}
+	for _, w := range s.Widgets {
+		title, _ := w["title_text"].(string)
+		requests = append(requests, graphRequests(title, w["tile_def"])...)
+	}
+	return requests
+}
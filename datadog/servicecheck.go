@@ -0,0 +1,27 @@
+package datadog
+
+import (
+	"net/http"
+)
+
+// Service check statuses.
+const (
+	ServiceCheckOK       = 0
+	ServiceCheckWarning  = 1
+	ServiceCheckCritical = 2
+	ServiceCheckUnknown  = 3
+)
+
+type ServiceCheck struct {
+	Check     string   `json:"check"`
+	HostName  string   `json:"host_name"`
+	Status    int      `json:"status"`
+	Timestamp int64    `json:"timestamp,omitempty"`
+	Message   string   `json:"message,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// PostServiceCheck submits the status of a service check.
+func (d API) PostServiceCheck(check ServiceCheck) error {
+	return d.doJSON(http.MethodPost, "/api/v1/check_run", nil, check, nil)
+}
@@ -0,0 +1,53 @@
+package datadog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostServiceCheck(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "POST", r.Method)
+		require.Equal(t, "/api/v1/check_run", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{
+			"check": "batch.nightly_export",
+			"host_name": "worker-1",
+			"status": 2,
+			"message": "Export failed",
+			"tags": ["env:prod"]
+		}`, string(b))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"status": "ok"}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	err := api.PostServiceCheck(ServiceCheck{
+		Check:    "batch.nightly_export",
+		HostName: "worker-1",
+		Status:   ServiceCheckCritical,
+		Message:  "Export failed",
+		Tags:     []string{"env:prod"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+}
@@ -0,0 +1,142 @@
+package datadog
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type SLO struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags,omitempty"`
+	// Query is set for metric based SLOs.
+	Query *SLOQuery `json:"query,omitempty"`
+	// MonitorIDs and Groups are set for monitor based SLOs.
+	MonitorIDs []int          `json:"monitor_ids,omitempty"`
+	Groups     []string       `json:"groups,omitempty"`
+	Thresholds []SLOThreshold `json:"thresholds"`
+	CreatedAt  int64          `json:"created_at,omitempty"`
+	ModifiedAt int64          `json:"modified_at,omitempty"`
+}
+
+type SLOQuery struct {
+	Numerator   string `json:"numerator"`
+	Denominator string `json:"denominator"`
+}
+
+type SLOThreshold struct {
+	// Timeframe is one of 7d, 30d or 90d.
+	Timeframe string   `json:"timeframe"`
+	Target    float64  `json:"target"`
+	Warning   *float64 `json:"warning,omitempty"`
+}
+
+// Threshold returns the SLO's threshold for the given timeframe, e.g. 30d.
+func (s SLO) Threshold(timeframe string) (SLOThreshold, bool) {
+	for _, t := range s.Thresholds {
+		if t.Timeframe == timeframe {
+			return t, true
+		}
+	}
+	return SLOThreshold{}, false
+}
+
+type SLOHistory struct {
+	FromTS  int64 `json:"from_ts"`
+	ToTS    int64 `json:"to_ts"`
+	Overall struct {
+		// SLIValue is the percentage of good events or uptime, e.g. 99.95.
+		SLIValue *float64 `json:"sli_value"`
+	} `json:"overall"`
+}
+
+// ErrorBudget returns the percentage of error budget remaining for an SLI
+// against a target, which is negative once the budget is spent, and the burn
+// rate, where 1 spends exactly the whole budget over the period.
+func ErrorBudget(target float64, sli float64) (remaining float64, burnRate float64) {
+	budget := 100 - target
+	if budget <= 0 {
+		if sli >= 100 {
+			return 100, 0
+		}
+		return 0, 0
+	}
+	burnRate = (100 - sli) / budget
+	return (1 - burnRate) * 100, burnRate
+}
+
+// sloPageSize is the number of SLOs to fetch per request.
+const sloPageSize = 1000
+
+func (d API) GetSLOs() ([]SLO, error) {
+	slos := []SLO{}
+	for offset := 0; ; offset += sloPageSize {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(sloPageSize))
+		query.Set("offset", strconv.Itoa(offset))
+		resp := struct {
+			Data []SLO `json:"data"`
+		}{}
+		if err := d.doJSON(http.MethodGet, "/api/v1/slo", query, nil, &resp); err != nil {
+			return nil, err
+		}
+		slos = append(slos, resp.Data...)
+		if len(resp.Data) < sloPageSize {
+			return slos, nil
+		}
+	}
+}
+
+func (d API) GetSLO(id string) (*SLO, error) {
+	resp := struct {
+		Data SLO `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/slo/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
+
+func (d API) CreateSLO(slo SLO) (*SLO, error) {
+	return d.saveSLO(http.MethodPost, "/api/v1/slo", slo)
+}
+
+func (d API) UpdateSLO(id string, slo SLO) (*SLO, error) {
+	return d.saveSLO(http.MethodPut, "/api/v1/slo/"+url.PathEscape(id), slo)
+}
+
+// saveSLO creates or updates an SLO, which both respond with a list of SLOs.
+func (d API) saveSLO(method string, endpoint string, slo SLO) (*SLO, error) {
+	resp := struct {
+		Data []SLO `json:"data"`
+	}{}
+	if err := d.doJSON(method, endpoint, nil, slo, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, errors.New("no SLO in response to " + method + " " + endpoint)
+	}
+	return &resp.Data[0], nil
+}
+
+func (d API) DeleteSLO(id string) error {
+	return d.doJSON(http.MethodDelete, "/api/v1/slo/"+url.PathEscape(id), nil, nil, nil)
+}
+
+func (d API) GetSLOHistory(id string, from time.Time, to time.Time) (*SLOHistory, error) {
+	query := url.Values{}
+	query.Set("from_ts", strconv.FormatInt(from.Unix(), 10))
+	query.Set("to_ts", strconv.FormatInt(to.Unix(), 10))
+	resp := struct {
+		Data SLOHistory `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/slo/"+url.PathEscape(id)+"/history", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
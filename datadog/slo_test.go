@@ -0,0 +1,181 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSLOs(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/slo", r.URL.Path)
+		require.Equal(t, "1000", r.URL.Query().Get("limit"))
+
+		// a full first page, then a partial second page
+		slos := []SLO{}
+		count := sloPageSize
+		if r.URL.Query().Get("offset") == "1000" {
+			count = 2
+		} else {
+			require.Equal(t, "0", r.URL.Query().Get("offset"))
+		}
+		for i := 0; i < count; i++ {
+			slos = append(slos, SLO{ID: fmt.Sprintf("slo-%d", i), Name: "SLO", Type: "metric"})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": slos})
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	slos, err := api.GetSLOs()
+	require.NoError(t, err)
+	require.Equal(t, 2, requestCount)
+	require.Len(t, slos, sloPageSize+2)
+}
+
+func TestGetSLO(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/slo/abc123", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"data": {
+				"id": "abc123",
+				"name": "API availability",
+				"type": "metric",
+				"tags": ["team:api"],
+				"query": {"numerator": "sum:api.requests.ok{*}.as_count()", "denominator": "sum:api.requests{*}.as_count()"},
+				"thresholds": [
+					{"timeframe": "7d", "target": 99.9},
+					{"timeframe": "30d", "target": 99.5, "warning": 99.8}
+				]
+			}
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	slo, err := api.GetSLO("abc123")
+	require.NoError(t, err)
+	require.Equal(t, "API availability", slo.Name)
+	require.Equal(t, "sum:api.requests{*}.as_count()", slo.Query.Denominator)
+
+	threshold, ok := slo.Threshold("30d")
+	require.True(t, ok)
+	require.Equal(t, 99.5, threshold.Target)
+	require.Equal(t, 99.8, *threshold.Warning)
+	_, ok = slo.Threshold("90d")
+	require.False(t, ok)
+}
+
+func TestSaveAndDeleteSLO(t *testing.T) {
+	requests := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "DELETE" {
+			fmt.Fprint(w, `{"data": ["abc123"]}`)
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		slo := SLO{}
+		require.NoError(t, json.Unmarshal(b, &slo))
+		require.Equal(t, "Checkout uptime", slo.Name)
+		require.Equal(t, []int{42}, slo.MonitorIDs)
+		slo.ID = "abc123"
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []SLO{slo}})
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	slo := SLO{
+		Name:       "Checkout uptime",
+		Type:       "monitor",
+		MonitorIDs: []int{42},
+		Thresholds: []SLOThreshold{{Timeframe: "30d", Target: 99.9}},
+	}
+	created, err := api.CreateSLO(slo)
+	require.NoError(t, err)
+	require.Equal(t, "abc123", created.ID)
+
+	updated, err := api.UpdateSLO("abc123", slo)
+	require.NoError(t, err)
+	require.Equal(t, "abc123", updated.ID)
+
+	require.NoError(t, api.DeleteSLO("abc123"))
+	require.Equal(t, []string{"POST /api/v1/slo", "PUT /api/v1/slo/abc123", "DELETE /api/v1/slo/abc123"}, requests)
+}
+
+func TestGetSLOHistory(t *testing.T) {
+	from := time.Unix(1600000000, 0)
+	to := time.Unix(1602592000, 0)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/slo/abc123/history", r.URL.Path)
+		require.Equal(t, "1600000000", r.URL.Query().Get("from_ts"))
+		require.Equal(t, "1602592000", r.URL.Query().Get("to_ts"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data": {"from_ts": 1600000000, "to_ts": 1602592000, "overall": {"sli_value": 99.75, "span_precision": 2}}}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	history, err := api.GetSLOHistory("abc123", from, to)
+	require.NoError(t, err)
+	require.Equal(t, 99.75, *history.Overall.SLIValue)
+}
+
+func TestErrorBudget(t *testing.T) {
+	tests := []struct {
+		target    float64
+		sli       float64
+		remaining float64
+		burnRate  float64
+	}{
+		{99.5, 100, 100, 0},
+		{99.5, 99.75, 50, 0.5},
+		{99.5, 99.5, 0, 1},
+		{99.5, 99, -100, 2},
+		{100, 100, 100, 0},
+		{100, 99.9, 0, 0},
+	}
+	for _, test := range tests {
+		remaining, burnRate := ErrorBudget(test.target, test.sli)
+		require.InDelta(t, test.remaining, remaining, 0.0001, "target %v, sli %v", test.target, test.sli)
+		require.InDelta(t, test.burnRate, burnRate, 0.0001, "target %v, sli %v", test.target, test.sli)
+	}
+}
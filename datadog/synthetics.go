@@ -0,0 +1,120 @@
+package datadog
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// SyntheticsTest is an API or browser test. Config and Options depend on the
+// test type, so are kept as they are returned.
+type SyntheticsTest struct {
+	PublicID  string                 `json:"public_id"`
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	Subtype   string                 `json:"subtype,omitempty"`
+	Status    string                 `json:"status"`
+	Message   string                 `json:"message"`
+	Tags      []string               `json:"tags"`
+	Locations []string               `json:"locations"`
+	MonitorID int                    `json:"monitor_id,omitempty"`
+	Config    map[string]interface{} `json:"config"`
+	Options   map[string]interface{} `json:"options"`
+}
+
+type SyntheticsGlobalVariable struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Value       struct {
+		Secure bool `json:"secure"`
+		// Value is empty for secure variables.
+		Value string `json:"value,omitempty"`
+	} `json:"value"`
+	ParseTestPublicID string                 `json:"parse_test_public_id,omitempty"`
+	ParseTestOptions  map[string]interface{} `json:"parse_test_options,omitempty"`
+}
+
+// Synthetics CI batch and result statuses.
+const (
+	SyntheticsStatusInProgress = "in_progress"
+	SyntheticsStatusPassed     = "passed"
+	SyntheticsStatusFailed     = "failed"
+	SyntheticsStatusSkipped    = "skipped"
+)
+
+// SyntheticsBatch is a set of test runs started by TriggerSyntheticsTests.
+type SyntheticsBatch struct {
+	Status  string                  `json:"status"`
+	Results []SyntheticsBatchResult `json:"results"`
+}
+
+type SyntheticsBatchResult struct {
+	TestPublicID string  `json:"test_public_id"`
+	TestName     string  `json:"test_name"`
+	ResultID     string  `json:"result_id"`
+	Status       string  `json:"status"`
+	Location     string  `json:"location"`
+	Device       string  `json:"device,omitempty"`
+	Duration     float64 `json:"duration"`
+	Retries      float64 `json:"retries"`
+}
+
+func (d API) GetSyntheticsTests() ([]SyntheticsTest, error) {
+	resp := struct {
+		Tests []SyntheticsTest `json:"tests"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/synthetics/tests", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Tests, nil
+}
+
+func (d API) GetSyntheticsTest(publicID string) (*SyntheticsTest, error) {
+	test := SyntheticsTest{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/synthetics/tests/"+url.PathEscape(publicID), nil, nil, &test); err != nil {
+		return nil, err
+	}
+	return &test, nil
+}
+
+func (d API) GetSyntheticsGlobalVariables() ([]SyntheticsGlobalVariable, error) {
+	resp := struct {
+		Variables []SyntheticsGlobalVariable `json:"variables"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/synthetics/variables", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Variables, nil
+}
+
+// TriggerSyntheticsTests starts CI runs of the given tests, returning the ID of
+// the batch to poll with GetSyntheticsBatch.
+func (d API) TriggerSyntheticsTests(publicIDs []string) (string, error) {
+	type test struct {
+		PublicID string `json:"public_id"`
+	}
+	req := struct {
+		Tests []test `json:"tests"`
+	}{}
+	for _, id := range publicIDs {
+		req.Tests = append(req.Tests, test{id})
+	}
+	resp := struct {
+		BatchID string `json:"batch_id"`
+	}{}
+	if err := d.doJSON(http.MethodPost, "/api/v1/synthetics/tests/trigger/ci", nil, req, &resp); err != nil {
+		return "", err
+	}
+	return resp.BatchID, nil
+}
+
+func (d API) GetSyntheticsBatch(batchID string) (*SyntheticsBatch, error) {
+	resp := struct {
+		Data SyntheticsBatch `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v1/synthetics/ci/batch/"+url.PathEscape(batchID), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.Data, nil
+}
@@ -0,0 +1,146 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSyntheticsTests(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/synthetics/tests", r.URL.Path)
+		require.Equal(t, "api-key", r.URL.Query().Get("api_key"))
+		require.Equal(t, "app-key", r.URL.Query().Get("application_key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"tests": [
+				{
+					"public_id": "abc-def-ghi",
+					"name": "Homepage",
+					"type": "api",
+					"subtype": "http",
+					"status": "live",
+					"tags": ["team:web"],
+					"locations": ["aws:us-east-1"],
+					"monitor_id": 1234,
+					"config": {"request": {"method": "GET", "url": "https://example.com"}},
+					"options": {"tick_every": 60}
+				},
+				{
+					"public_id": "jkl-mno-pqr",
+					"name": "Checkout",
+					"type": "browser",
+					"status": "paused"
+				}
+			]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	tests, err := api.GetSyntheticsTests()
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, tests, 2)
+	require.Equal(t, "abc-def-ghi", tests[0].PublicID)
+	require.Equal(t, "http", tests[0].Subtype)
+	require.Equal(t, 1234, tests[0].MonitorID)
+	require.Equal(t, 60.0, tests[0].Options["tick_every"])
+	require.Equal(t, "browser", tests[1].Type)
+	require.Equal(t, "paused", tests[1].Status)
+}
+
+func TestGetSyntheticsGlobalVariables(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v1/synthetics/variables", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"variables": [
+				{"id": "v1", "name": "BASE_URL", "tags": [], "value": {"secure": false, "value": "https://example.com"}},
+				{"id": "v2", "name": "PASSWORD", "tags": ["team:web"], "value": {"secure": true}}
+			]
+		}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	variables, err := api.GetSyntheticsGlobalVariables()
+	require.NoError(t, err)
+	require.Equal(t, 1, requestCount)
+	require.Len(t, variables, 2)
+	require.Equal(t, "https://example.com", variables[0].Value.Value)
+	require.True(t, variables[1].Value.Secure)
+	require.Equal(t, "", variables[1].Value.Value)
+}
+
+func TestTriggerSyntheticsTests(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/synthetics/tests/trigger/ci":
+			require.Equal(t, "POST", r.Method)
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"tests": [{"public_id": "abc-def-ghi"}, {"public_id": "jkl-mno-pqr"}]}`, string(b))
+			fmt.Fprint(w, `{"batch_id": "batch-1", "results": [], "triggered_check_ids": ["abc-def-ghi", "jkl-mno-pqr"]}`)
+		case "/api/v1/synthetics/ci/batch/batch-1":
+			require.Equal(t, "GET", r.Method)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"status": "failed",
+					"results": []map[string]interface{}{
+						{"test_public_id": "abc-def-ghi", "test_name": "Homepage", "result_id": "1", "status": "passed", "location": "aws:us-east-1", "duration": 120.5},
+						{"test_public_id": "jkl-mno-pqr", "test_name": "Checkout", "result_id": "2", "status": "failed", "location": "aws:us-east-1", "device": "chrome.laptop_large"},
+					},
+				},
+			})
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	batchID, err := api.TriggerSyntheticsTests([]string{"abc-def-ghi", "jkl-mno-pqr"})
+	require.NoError(t, err)
+	require.Equal(t, "batch-1", batchID)
+
+	batch, err := api.GetSyntheticsBatch(batchID)
+	require.NoError(t, err)
+	require.Equal(t, 2, requestCount)
+	require.Equal(t, SyntheticsStatusFailed, batch.Status)
+	require.Len(t, batch.Results, 2)
+	require.Equal(t, SyntheticsStatusPassed, batch.Results[0].Status)
+	require.Equal(t, 120.5, batch.Results[0].Duration)
+	require.Equal(t, "chrome.laptop_large", batch.Results[1].Device)
+}
@@ -0,0 +1,255 @@
+package datadog
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Creator is the user who created an item, as embedded in monitors and
+// dashboards.
+type Creator struct {
+	ID     int    `json:"id"`
+	Handle string `json:"handle"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	// Disabled is only set for the creators of dashboards and screenboards.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+type User struct {
+	ID             string     `json:"id"`
+	Handle         string     `json:"handle"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	Disabled       bool       `json:"disabled"`
+	Verified       bool       `json:"verified"`
+	ServiceAccount bool       `json:"service_account"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ModifiedAt     time.Time  `json:"modified_at"`
+	LastLoginTime  *time.Time `json:"last_login_time,omitempty"`
+	// Roles are the names of the user's roles.
+	Roles []string `json:"roles"`
+}
+
+type Role struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	UserCount  int       `json:"user_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	ModifiedAt time.Time `json:"modified_at"`
+	// Permissions are the names of the role's permissions, e.g. dashboards_write.
+	Permissions []string `json:"permissions"`
+}
+
+type Team struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Handle      string    `json:"handle"`
+	Description string    `json:"description"`
+	UserCount   int       `json:"user_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	ModifiedAt  time.Time `json:"modified_at"`
+	// Members are the handles of the team's members.
+	Members []string `json:"members"`
+}
+
+// accessPageSize is the number of users, roles or teams to fetch per request.
+const accessPageSize = 100
+
+// relationship is a reference to another resource in a v2 API response.
+type relationship struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+func (d API) GetUsers() ([]User, error) {
+	users := []User{}
+	for page := 0; ; page++ {
+		resp := struct {
+			Data []struct {
+				ID         string `json:"id"`
+				Attributes User   `json:"attributes"`
+				Relations  struct {
+					Roles struct {
+						Data []relationship `json:"data"`
+					} `json:"roles"`
+				} `json:"relationships"`
+			} `json:"data"`
+			Included []struct {
+				relationship
+				Attributes struct {
+					Name string `json:"name"`
+				} `json:"attributes"`
+			} `json:"included"`
+		}{}
+		if err := d.doJSON(http.MethodGet, "/api/v2/users", pageQuery(page), nil, &resp); err != nil {
+			return nil, err
+		}
+
+		roleNames := map[string]string{}
+		for _, inc := range resp.Included {
+			if inc.Type == "roles" {
+				roleNames[inc.ID] = inc.Attributes.Name
+			}
+		}
+		for _, data := range resp.Data {
+			user := data.Attributes
+			user.ID = data.ID
+			user.Roles = []string{}
+			for _, role := range data.Relations.Roles.Data {
+				name, ok := roleNames[role.ID]
+				if !ok {
+					name = role.ID
+				}
+				user.Roles = append(user.Roles, name)
+			}
+			users = append(users, user)
+		}
+		if len(resp.Data) < accessPageSize {
+			return users, nil
+		}
+	}
+}
+
+func (d API) GetRoles() ([]Role, error) {
+	permissionNames, err := d.getPermissionNames()
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []Role{}
+	for page := 0; ; page++ {
+		resp := struct {
+			Data []struct {
+				ID         string `json:"id"`
+				Attributes Role   `json:"attributes"`
+				Relations  struct {
+					Permissions struct {
+						Data []relationship `json:"data"`
+					} `json:"permissions"`
+				} `json:"relationships"`
+			} `json:"data"`
+		}{}
+		if err := d.doJSON(http.MethodGet, "/api/v2/roles", pageQuery(page), nil, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, data := range resp.Data {
+			role := data.Attributes
+			role.ID = data.ID
+			role.Permissions = []string{}
+			for _, permission := range data.Relations.Permissions.Data {
+				name, ok := permissionNames[permission.ID]
+				if !ok {
+					name = permission.ID
+				}
+				role.Permissions = append(role.Permissions, name)
+			}
+			roles = append(roles, role)
+		}
+		if len(resp.Data) < accessPageSize {
+			return roles, nil
+		}
+	}
+}
+
+func (d API) GetTeams() ([]Team, error) {
+	teams := []Team{}
+	for page := 0; ; page++ {
+		resp := struct {
+			Data []struct {
+				ID         string `json:"id"`
+				Attributes Team   `json:"attributes"`
+			} `json:"data"`
+		}{}
+		if err := d.doJSON(http.MethodGet, "/api/v2/team", pageQuery(page), nil, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, data := range resp.Data {
+			team := data.Attributes
+			team.ID = data.ID
+			members, err := d.getTeamMembers(team.ID)
+			if err != nil {
+				return nil, err
+			}
+			team.Members = members
+			teams = append(teams, team)
+		}
+		if len(resp.Data) < accessPageSize {
+			return teams, nil
+		}
+	}
+}
+
+// getTeamMembers returns the handles of a team's members.
+func (d API) getTeamMembers(teamID string) ([]string, error) {
+	members := []string{}
+	for page := 0; ; page++ {
+		resp := struct {
+			Data []struct {
+				Relations struct {
+					User struct {
+						Data relationship `json:"data"`
+					} `json:"user"`
+				} `json:"relationships"`
+			} `json:"data"`
+			Included []struct {
+				relationship
+				Attributes struct {
+					Handle string `json:"handle"`
+				} `json:"attributes"`
+			} `json:"included"`
+		}{}
+		if err := d.doJSON(http.MethodGet, "/api/v2/team/"+url.PathEscape(teamID)+"/memberships", pageQuery(page), nil, &resp); err != nil {
+			return nil, err
+		}
+
+		handles := map[string]string{}
+		for _, inc := range resp.Included {
+			if inc.Type == "users" {
+				handles[inc.ID] = inc.Attributes.Handle
+			}
+		}
+		for _, data := range resp.Data {
+			handle, ok := handles[data.Relations.User.Data.ID]
+			if !ok {
+				handle = data.Relations.User.Data.ID
+			}
+			members = append(members, handle)
+		}
+		if len(resp.Data) < accessPageSize {
+			return members, nil
+		}
+	}
+}
+
+// getPermissionNames returns the names of all permissions by ID.
+func (d API) getPermissionNames() (map[string]string, error) {
+	resp := struct {
+		Data []struct {
+			ID         string `json:"id"`
+			Attributes struct {
+				Name string `json:"name"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}{}
+	if err := d.doJSON(http.MethodGet, "/api/v2/permissions", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, data := range resp.Data {
+		names[data.ID] = data.Attributes.Name
+	}
+	return names, nil
+}
+
+func pageQuery(page int) url.Values {
+	query := url.Values{}
+	query.Set("page[size]", strconv.Itoa(accessPageSize))
+	query.Set("page[number]", strconv.Itoa(page))
+	return query
+}
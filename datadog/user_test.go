@@ -0,0 +1,192 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUsers(t *testing.T) {
+	requestCount := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "/api/v2/users", r.URL.Path)
+		require.Equal(t, "100", r.URL.Query().Get("page[size]"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page[number]") == "1" {
+			fmt.Fprint(w, `{"data": [], "included": []}`)
+			return
+		}
+		require.Equal(t, "0", r.URL.Query().Get("page[number]"))
+
+		// a full first page, so a second page is requested
+		data := []map[string]interface{}{
+			{
+				"id":   "u1",
+				"type": "users",
+				"attributes": map[string]interface{}{
+					"handle":          "jane@example.com",
+					"name":            "Jane",
+					"email":           "jane@example.com",
+					"status":          "Active",
+					"created_at":      "2020-01-02T03:04:05.000000+00:00",
+					"modified_at":     "2020-01-02T03:04:05.000000+00:00",
+					"last_login_time": "2020-10-01T00:00:00Z",
+				},
+				"relationships": map[string]interface{}{
+					"roles": map[string]interface{}{"data": []map[string]string{{"id": "r1", "type": "roles"}}},
+				},
+			},
+		}
+		for i := 1; i < accessPageSize; i++ {
+			data = append(data, map[string]interface{}{
+				"id":   fmt.Sprintf("u%d", i+1),
+				"type": "users",
+				"attributes": map[string]interface{}{
+					"handle":      fmt.Sprintf("user%d@example.com", i+1),
+					"status":      "Disabled",
+					"disabled":    true,
+					"created_at":  "2020-01-02T03:04:05.000000+00:00",
+					"modified_at": "2020-01-02T03:04:05.000000+00:00",
+				},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": data,
+			"included": []map[string]interface{}{
+				{"id": "r1", "type": "roles", "attributes": map[string]string{"name": "Datadog Admin Role"}},
+			},
+		})
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	users, err := api.GetUsers()
+	require.NoError(t, err)
+	require.Equal(t, 2, requestCount)
+	require.Len(t, users, accessPageSize)
+
+	require.Equal(t, "u1", users[0].ID)
+	require.Equal(t, "jane@example.com", users[0].Handle)
+	require.Equal(t, "Active", users[0].Status)
+	require.Equal(t, []string{"Datadog Admin Role"}, users[0].Roles)
+	require.Equal(t, time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC), users[0].LastLoginTime.UTC())
+
+	require.True(t, users[1].Disabled)
+	require.Nil(t, users[1].LastLoginTime)
+	require.Equal(t, []string{}, users[1].Roles)
+}
+
+func TestGetRoles(t *testing.T) {
+	requests := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		require.Equal(t, "GET", r.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/permissions":
+			fmt.Fprint(w, `{
+				"data": [
+					{"id": "p1", "type": "permissions", "attributes": {"name": "dashboards_write"}},
+					{"id": "p2", "type": "permissions", "attributes": {"name": "monitors_write"}}
+				]
+			}`)
+		case "/api/v2/roles":
+			require.Equal(t, "0", r.URL.Query().Get("page[number]"))
+			fmt.Fprint(w, `{
+				"data": [
+					{
+						"id": "r1",
+						"type": "roles",
+						"attributes": {"name": "Editors", "user_count": 3, "created_at": "2020-01-02T03:04:05.000000+00:00", "modified_at": "2020-01-02T03:04:05.000000+00:00"},
+						"relationships": {"permissions": {"data": [{"id": "p1", "type": "permissions"}, {"id": "p9", "type": "permissions"}]}}
+					}
+				]
+			}`)
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	roles, err := api.GetRoles()
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api/v2/permissions", "/api/v2/roles"}, requests)
+	require.Len(t, roles, 1)
+	require.Equal(t, "r1", roles[0].ID)
+	require.Equal(t, "Editors", roles[0].Name)
+	require.Equal(t, 3, roles[0].UserCount)
+	// unknown permissions are listed by ID
+	require.Equal(t, []string{"dashboards_write", "p9"}, roles[0].Permissions)
+}
+
+func TestGetTeams(t *testing.T) {
+	requests := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		require.Equal(t, "GET", r.Method)
+		require.Equal(t, "0", r.URL.Query().Get("page[number]"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/team":
+			fmt.Fprint(w, `{
+				"data": [
+					{
+						"id": "t1",
+						"type": "team",
+						"attributes": {"name": "Checkout", "handle": "checkout", "description": "Payments and carts", "user_count": 2, "created_at": "2020-01-02T03:04:05.000000+00:00", "modified_at": "2020-01-02T03:04:05.000000+00:00"}
+					}
+				]
+			}`)
+		case "/api/v2/team/t1/memberships":
+			fmt.Fprint(w, `{
+				"data": [
+					{"id": "m1", "type": "team_memberships", "relationships": {"user": {"data": {"id": "u1", "type": "users"}}}},
+					{"id": "m2", "type": "team_memberships", "relationships": {"user": {"data": {"id": "u9", "type": "users"}}}}
+				],
+				"included": [
+					{"id": "u1", "type": "users", "attributes": {"handle": "jane@example.com"}}
+				]
+			}`)
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := API{
+		apiKey:  "api-key",
+		appKey:  "app-key",
+		baseURL: server.URL,
+	}
+
+	teams, err := api.GetTeams()
+	require.NoError(t, err)
+	require.Equal(t, []string{"/api/v2/team", "/api/v2/team/t1/memberships"}, requests)
+	require.Len(t, teams, 1)
+	require.Equal(t, "t1", teams[0].ID)
+	require.Equal(t, "checkout", teams[0].Handle)
+	require.Equal(t, 2, teams[0].UserCount)
+	// members not included in the response are listed by ID
+	require.Equal(t, []string{"jane@example.com", "u9"}, teams[0].Members)
+}
@@ -0,0 +1,138 @@
+// Package dogstatsd parses metrics and events in the DogStatsD datagram
+// format, so lines written for a local agent can be submitted to the API
+// instead.
+package dogstatsd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+)
+
+var metricTypes = map[string]string{
+	"g": "gauge",
+	"c": "count",
+}
+
+// ParseMetric parses a metric line such as
+// `deploy.count:1|c|@0.5|#service:checkout,env:prod`. Only gauges and counts
+// are supported. Counts are scaled up by their sample rate. The point is
+// timestamped with now unless the line has a T<unix seconds> field.
+func ParseMetric(line string, now time.Time) (datadog.SubmittedSeries, error) {
+	fields := strings.Split(strings.TrimSpace(line), "|")
+	if len(fields) < 2 {
+		return datadog.SubmittedSeries{}, errors.New("expected name:value|type")
+	}
+
+	i := strings.LastIndex(fields[0], ":")
+	if i <= 0 {
+		return datadog.SubmittedSeries{}, errors.New("expected name:value|type")
+	}
+	value, err := strconv.ParseFloat(fields[0][i+1:], 64)
+	if err != nil {
+		return datadog.SubmittedSeries{}, fmt.Errorf("invalid value %q", fields[0][i+1:])
+	}
+
+	metricType, ok := metricTypes[fields[1]]
+	if !ok {
+		return datadog.SubmittedSeries{}, fmt.Errorf("unsupported metric type %q, only g and c are supported", fields[1])
+	}
+
+	series := datadog.SubmittedSeries{
+		Metric: fields[0][:i],
+		Type:   metricType,
+	}
+	timestamp := now.Unix()
+	for _, field := range fields[2:] {
+		switch {
+		case strings.HasPrefix(field, "@"):
+			rate, err := strconv.ParseFloat(field[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return datadog.SubmittedSeries{}, fmt.Errorf("invalid sample rate %q", field[1:])
+			}
+			if metricType == "count" {
+				value = value / rate
+			}
+		case strings.HasPrefix(field, "#"):
+			series.Tags = strings.Split(field[1:], ",")
+		case strings.HasPrefix(field, "T"):
+			timestamp, err = strconv.ParseInt(field[1:], 10, 64)
+			if err != nil {
+				return datadog.SubmittedSeries{}, fmt.Errorf("invalid timestamp %q", field[1:])
+			}
+		default:
+			return datadog.SubmittedSeries{}, fmt.Errorf("unknown field %q", field)
+		}
+	}
+	series.Points = [][2]float64{{float64(timestamp), value}}
+	return series, nil
+}
+
+// ParseEvent parses an event line such as
+// `_e{17,13}:Deployed checkout|Version 1.2.3|p:low|t:info|#service:checkout`.
+// Escaped newlines (\n) in the text are unescaped.
+func ParseEvent(line string) (datadog.Event, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "_e{") {
+		return datadog.Event{}, errors.New("expected _e{title length,text length}:title|text")
+	}
+	end := strings.Index(line, "}:")
+	if end < 0 {
+		return datadog.Event{}, errors.New("expected _e{title length,text length}:title|text")
+	}
+	lengths := strings.Split(line[3:end], ",")
+	if len(lengths) != 2 {
+		return datadog.Event{}, errors.New("expected _e{title length,text length}:title|text")
+	}
+	titleLen, err1 := strconv.Atoi(lengths[0])
+	textLen, err2 := strconv.Atoi(lengths[1])
+	if err1 != nil || err2 != nil || titleLen < 0 || textLen < 0 {
+		return datadog.Event{}, fmt.Errorf("invalid lengths %q", line[3:end])
+	}
+
+	rest := line[end+2:]
+	if len(rest) < titleLen+1+textLen || rest[titleLen] != '|' {
+		return datadog.Event{}, errors.New("title and text don't match their lengths")
+	}
+	event := datadog.Event{
+		Title: rest[:titleLen],
+		Text:  strings.Replace(rest[titleLen+1:titleLen+1+textLen], "\\n", "\n", -1),
+	}
+
+	rest = rest[titleLen+1+textLen:]
+	if rest == "" {
+		return event, nil
+	}
+	if rest[0] != '|' {
+		return datadog.Event{}, errors.New("title and text don't match their lengths")
+	}
+	for _, field := range strings.Split(rest[1:], "|") {
+		switch {
+		case strings.HasPrefix(field, "d:"):
+			date, err := strconv.ParseInt(field[2:], 10, 64)
+			if err != nil {
+				return datadog.Event{}, fmt.Errorf("invalid timestamp %q", field[2:])
+			}
+			event.DateHappened = date
+		case strings.HasPrefix(field, "h:"):
+			event.Host = field[2:]
+		case strings.HasPrefix(field, "k:"):
+			event.AggregationKey = field[2:]
+		case strings.HasPrefix(field, "p:"):
+			event.Priority = field[2:]
+		case strings.HasPrefix(field, "s:"):
+			event.SourceTypeName = field[2:]
+		case strings.HasPrefix(field, "t:"):
+			event.AlertType = field[2:]
+		case strings.HasPrefix(field, "#"):
+			event.Tags = strings.Split(field[1:], ",")
+		default:
+			return datadog.Event{}, fmt.Errorf("unknown field %q", field)
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,92 @@
+package dogstatsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetric(t *testing.T) {
+	now := time.Unix(1545717600, 0)
+	tests := []struct {
+		input    string
+		expected datadog.SubmittedSeries
+	}{
+		{
+			"deploy.count:1|c",
+			datadog.SubmittedSeries{Metric: "deploy.count", Type: "count", Points: [][2]float64{{1545717600, 1}}},
+		},
+		{
+			"queue.depth:12.5|g|#env:prod,queue:jobs",
+			datadog.SubmittedSeries{Metric: "queue.depth", Type: "gauge", Points: [][2]float64{{1545717600, 12.5}}, Tags: []string{"env:prod", "queue:jobs"}},
+		},
+		{
+			"requests:5|c|@0.5|T1545717000",
+			datadog.SubmittedSeries{Metric: "requests", Type: "count", Points: [][2]float64{{1545717000, 10}}},
+		},
+	}
+
+	for _, test := range tests {
+		series, err := ParseMetric(test.input, now)
+		require.NoError(t, err, test.input)
+		require.Equal(t, test.expected, series, test.input)
+	}
+}
+
+func TestParseMetricErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"deploy.count", "expected name:value|type"},
+		{"deploy.count:one|c", `invalid value "one"`},
+		{"latency:20|ms", `unsupported metric type "ms", only g and c are supported`},
+		{"requests:5|c|@2", `invalid sample rate "2"`},
+		{"requests:5|c|x", `unknown field "x"`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseMetric(test.input, time.Now())
+		require.EqualError(t, err, test.expected, test.input)
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	event, err := ParseEvent(`_e{17,20}:Deployed checkout|Version 1.2.3\nby CI|d:1545717600|h:ci-1|p:low|t:success|k:deploy|s:jenkins|#service:checkout,env:prod`)
+	require.NoError(t, err)
+	require.Equal(t, datadog.Event{
+		Title:          "Deployed checkout",
+		Text:           "Version 1.2.3\nby CI",
+		DateHappened:   1545717600,
+		Host:           "ci-1",
+		Priority:       "low",
+		AlertType:      "success",
+		AggregationKey: "deploy",
+		SourceTypeName: "jenkins",
+		Tags:           []string{"service:checkout", "env:prod"},
+	}, event)
+
+	event, err = ParseEvent("_e{5,3}:a|b|c|d|e")
+	require.NoError(t, err)
+	require.Equal(t, datadog.Event{Title: "a|b|c", Text: "d|e"}, event)
+}
+
+func TestParseEventErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Deployed", "expected _e{title length,text length}:title|text"},
+		{"_e{a,1}:x|y", `invalid lengths "a,1"`},
+		{"_e{5,1}:x|y", "title and text don't match their lengths"},
+		{"_e{1,1}:x|yz", "title and text don't match their lengths"},
+		{"_e{1,1}:x|y|q:1", `unknown field "q:1"`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseEvent(test.input)
+		require.EqualError(t, err, test.expected, test.input)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+var downtimeCommand = cli.Command{
+	Name:  "downtime",
+	Usage: "downtime commands",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list downtimes",
+			Action: listDowntimes,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "current-only",
+					Usage: "Only list active downtimes",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "csv",
+					Usage: "Format, either csv, md (markdown) or json",
+				},
+			},
+		},
+		{
+			Name:   "create",
+			Usage:  "schedule a downtime, printing its ID",
+			Action: createDowntime,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "scope, s",
+					Usage: "Scope to apply the downtime to, e.g. env:prod (repeatable)",
+				},
+				cli.IntFlag{
+					Name:  "monitor-id",
+					Usage: "Only silence the monitor with this ID",
+				},
+				cli.StringSliceFlag{
+					Name:  "monitor-tag",
+					Usage: "Only silence monitors with this tag (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "start",
+					Usage: "Start time as RFC3339 or a Unix timestamp (default now)",
+				},
+				cli.StringFlag{
+					Name:  "end",
+					Usage: "End time as RFC3339 or a Unix timestamp",
+				},
+				cli.DurationFlag{
+					Name:  "duration, d",
+					Usage: "Length of the downtime from the start, e.g. 30m, used if --end isn't given",
+				},
+				cli.StringFlag{
+					Name:  "message, m",
+					Usage: "Message to include with notifications for this downtime",
+				},
+				cli.StringFlag{
+					Name:  "recurrence",
+					Usage: "Recurrence type, one of days, weeks, months or years",
+				},
+				cli.IntFlag{
+					Name:  "recurrence-period",
+					Value: 1,
+					Usage: "How often to repeat, in units of the recurrence type",
+				},
+				cli.StringSliceFlag{
+					Name:  "recurrence-week-day",
+					Usage: "Day of the week to repeat on for weekly recurrence, e.g. Mon (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "recurrence-until",
+					Usage: "Stop repeating after this time, as RFC3339 or a Unix timestamp",
+				},
+				cli.StringFlag{
+					Name:  "rrule",
+					Usage: "iCalendar RRULE recurrence rule instead of --recurrence, e.g. FREQ=WEEKLY;BYDAY=MO, may be used with --recurrence-until",
+				},
+			},
+		},
+		{
+			Name:      "cancel",
+			Usage:     "cancel a downtime by ID",
+			ArgsUsage: "<id>",
+			Action:    cancelDowntime,
+		},
+		{
+			Name:      "cancel-by-scope",
+			Usage:     "cancel all downtimes matching a scope",
+			ArgsUsage: "<scope>",
+			Action:    cancelDowntimesByScope,
+		},
+	},
+}
+
+func listDowntimes(c *cli.Context) error {
+	api := getAPI()
+
+	downtimes, err := api.GetDowntimes(c.Bool("current-only"))
+	if err != nil {
+		return err
+	}
+
+	if c.String("format") == "json" {
+		return printJSON(downtimes)
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"ID", "Scope", "Monitor", "Start", "End", "Recurrence", "Message"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, d := range downtimes {
+		monitor := strings.Join(d.MonitorTags, " ")
+		if d.MonitorID != nil {
+			monitor = strconv.Itoa(*d.MonitorID)
+		}
+		end := ""
+		if d.End != nil {
+			end = formatUnix(*d.End)
+		}
+		recurrence := ""
+		if d.Recurrence != nil {
+			recurrence = d.Recurrence.RRule
+			if recurrence == "" {
+				recurrence = fmt.Sprintf("every %d %s", d.Recurrence.Period, d.Recurrence.Type)
+			}
+		}
+		if err := w.Write([]string{
+			strconv.Itoa(d.ID),
+			strings.Join(d.Scope, ","),
+			monitor,
+			formatUnix(d.Start),
+			end,
+			recurrence,
+			d.Message,
+		}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func createDowntime(c *cli.Context) error {
+	downtime := datadog.Downtime{
+		Scope:       c.StringSlice("scope"),
+		MonitorTags: c.StringSlice("monitor-tag"),
+		Message:     c.String("message"),
+	}
+	if len(downtime.Scope) == 0 {
+		downtime.Scope = []string{"*"}
+	}
+	if c.IsSet("monitor-id") {
+		id := c.Int("monitor-id")
+		downtime.MonitorID = &id
+	}
+
+	start := time.Now()
+	if c.String("start") != "" {
+		t, err := parseTime(c.String("start"))
+		if err != nil {
+			return errors.New("invalid start time: " + err.Error())
+		}
+		start = t
+	}
+	downtime.Start = start.Unix()
+
+	if c.String("end") != "" {
+		t, err := parseTime(c.String("end"))
+		if err != nil {
+			return errors.New("invalid end time: " + err.Error())
+		}
+		end := t.Unix()
+		downtime.End = &end
+	} else if c.Duration("duration") > 0 {
+		end := start.Add(c.Duration("duration")).Unix()
+		downtime.End = &end
+	}
+
+	if c.String("rrule") != "" {
+		// the rule sets the frequency and days itself
+		if c.String("recurrence") != "" || c.IsSet("recurrence-period") || len(c.StringSlice("recurrence-week-day")) > 0 {
+			return errors.New("--rrule can't be used with --recurrence, --recurrence-period or --recurrence-week-day")
+		}
+		downtime.Recurrence = &datadog.DowntimeRecurrence{
+			Type:  "rrule",
+			RRule: c.String("rrule"),
+		}
+	} else if c.String("recurrence") != "" {
+		downtime.Recurrence = &datadog.DowntimeRecurrence{
+			Type:     c.String("recurrence"),
+			Period:   c.Int("recurrence-period"),
+			WeekDays: c.StringSlice("recurrence-week-day"),
+		}
+	} else if c.String("recurrence-until") != "" || len(c.StringSlice("recurrence-week-day")) > 0 {
+		return errors.New("--recurrence or --rrule is required to repeat a downtime")
+	}
+	if downtime.Recurrence != nil && c.String("recurrence-until") != "" {
+		t, err := parseTime(c.String("recurrence-until"))
+		if err != nil {
+			return errors.New("invalid recurrence until time: " + err.Error())
+		}
+		until := t.Unix()
+		downtime.Recurrence.UntilDate = &until
+	}
+
+	api := getAPI()
+	created, err := api.CreateDowntime(downtime)
+	if err != nil {
+		return err
+	}
+	fmt.Println(created.ID)
+	return nil
+}
+
+func cancelDowntime(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("downtime ID required")
+	}
+	id, err := strconv.Atoi(c.Args()[0])
+	if err != nil {
+		return errors.New("invalid downtime ID: " + c.Args()[0])
+	}
+
+	api := getAPI()
+	return api.CancelDowntime(id)
+}
+
+func cancelDowntimesByScope(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("scope required")
+	}
+
+	api := getAPI()
+	ids, err := api.CancelDowntimesByScope(c.Args()[0])
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+	return nil
+}
+
+// parseTime parses s as either an RFC3339 time or a Unix timestamp in seconds.
+func parseTime(s string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func formatUnix(secs int64) string {
+	if secs == 0 {
+		return ""
+	}
+	return time.Unix(secs, 0).Format(time.RFC3339)
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/dogstatsd"
+	"github.com/urfave/cli"
+)
+
+var eventsCommand = cli.Command{
+	Name:  "events",
+	Usage: "event commands",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list events, oldest first",
+			Action: listEvents,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "since, s",
+					Value: "24h",
+					Usage: "Start time as a duration ago, e.g. 24h, RFC3339 or a Unix timestamp",
+				},
+				cli.StringFlag{
+					Name:  "until, u",
+					Usage: "End time as a duration ago, RFC3339 or a Unix timestamp (default now)",
+				},
+				cli.StringSliceFlag{
+					Name:  "tags, t",
+					Usage: "Only list events with this tag (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "priority",
+					Usage: "Only list events with this priority, either normal or low",
+				},
+				cli.StringSliceFlag{
+					Name:  "sources",
+					Usage: "Only list events from this source, e.g. jenkins (repeatable)",
+				},
+				cli.DurationFlag{
+					Name:  "window",
+					Value: 24 * time.Hour,
+					Usage: "Fetch events this much time at a time",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "csv",
+					Usage: "Format, one of csv, md (markdown) or jsonl (JSON lines)",
+				},
+			},
+		},
+		{
+			Name:   "post",
+			Usage:  "post an event, or events read from stdin as JSON or DogStatsD lines if --title isn't given",
+			Action: postEvent,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "title",
+					Usage: "Event title",
+				},
+				cli.StringFlag{
+					Name:  "text",
+					Usage: "Event text, which may use markdown if it starts with %%%",
+				},
+				cli.StringSliceFlag{
+					Name:  "tags, t",
+					Usage: "Tag to add to the event (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "priority",
+					Usage: "Priority, either normal or low",
+				},
+				cli.StringFlag{
+					Name:  "alert-type",
+					Usage: "Alert type, one of info, warning, error or success",
+				},
+				cli.StringFlag{
+					Name:  "host",
+					Usage: "Host the event is about",
+				},
+				cli.StringFlag{
+					Name:  "aggregation-key",
+					Usage: "Key to group related events by",
+				},
+				cli.StringFlag{
+					Name:  "source",
+					Usage: "Source type name, e.g. jenkins",
+				},
+			},
+		},
+	},
+}
+
+func postEvent(c *cli.Context) error {
+	api := getAPI()
+
+	if c.String("title") == "" {
+		return postEventsFromStdin(api)
+	}
+
+	event, err := api.PostEvent(datadog.Event{
+		Title:          c.String("title"),
+		Text:           c.String("text"),
+		Tags:           c.StringSlice("tags"),
+		Priority:       c.String("priority"),
+		AlertType:      c.String("alert-type"),
+		Host:           c.String("host"),
+		AggregationKey: c.String("aggregation-key"),
+		SourceTypeName: c.String("source"),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(event.URL)
+	return nil
+}
+
+// postEventsFromStdin posts events read from stdin, one per line, either as
+// JSON objects or in DogStatsD format.
+func postEventsFromStdin(api *datadog.API) error {
+	scanner := bufio.NewScanner(os.Stdin)
+	lineNumber := 0
+	posted := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var event datadog.Event
+		if strings.HasPrefix(line, "{") {
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				return fmt.Errorf("line %d: invalid JSON: %s", lineNumber, err.Error())
+			}
+		} else {
+			var err error
+			if event, err = dogstatsd.ParseEvent(line); err != nil {
+				return fmt.Errorf("line %d: %s", lineNumber, err.Error())
+			}
+		}
+		if event.Title == "" {
+			return fmt.Errorf("line %d: title required", lineNumber)
+		}
+
+		if _, err := api.PostEvent(event); err != nil {
+			return fmt.Errorf("line %d: %s", lineNumber, err.Error())
+		}
+		posted++
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.New("failed to read stdin: " + err.Error())
+	}
+	log.Printf("Posted %d events", posted)
+	return nil
+}
+
+func listEvents(c *cli.Context) error {
+	now := time.Now()
+	start, err := parseTimeOrAgo(c.String("since"), now)
+	if err != nil {
+		return errors.New("invalid --since: " + err.Error())
+	}
+	end := now
+	if c.String("until") != "" {
+		if end, err = parseTimeOrAgo(c.String("until"), now); err != nil {
+			return errors.New("invalid --until: " + err.Error())
+		}
+	}
+	filters := datadog.EventFilters{
+		Priority: c.String("priority"),
+		Sources:  c.StringSlice("sources"),
+		Tags:     c.StringSlice("tags"),
+	}
+
+	api := getAPI()
+
+	if c.String("format") == "jsonl" {
+		enc := json.NewEncoder(os.Stdout)
+		return getEventsByWindow(api, start, end, c.Duration("window"), filters, func(e datadog.Event) error {
+			return enc.Encode(e)
+		})
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Time", "ID", "Priority", "Alert type", "Source", "Title", "Tags"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	err = getEventsByWindow(api, start, end, c.Duration("window"), filters, func(e datadog.Event) error {
+		return w.Write([]string{
+			formatUnix(e.DateHappened),
+			strconv.FormatInt(e.ID, 10),
+			e.Priority,
+			e.AlertType,
+			e.SourceTypeName,
+			e.Title,
+			strings.Join(e.Tags, " "),
+		})
+	})
+	w.Flush()
+	return err
+}
+
+// getEventsByWindow fetches events between start and end, window by window so
+// that busy periods don't need to be held in memory at once, and calls fn
+// with each event, oldest first.
+func getEventsByWindow(api *datadog.API, start time.Time, end time.Time, window time.Duration, filters datadog.EventFilters, fn func(datadog.Event) error) error {
+	if window <= 0 {
+		window = end.Sub(start)
+	}
+	for from := start; from.Before(end); from = from.Add(window) {
+		to := from.Add(window)
+		if to.After(end) {
+			to = end
+		}
+		events, err := api.GetEvents(from, to, filters)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].DateHappened < events[j].DateHappened
+		})
+		for _, e := range events {
+			// windows share their boundary second, so skip events belonging to the next one
+			if e.DateHappened >= to.Unix() && !to.Equal(end) {
+				continue
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
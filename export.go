@@ -2,12 +2,15 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
+	"time"
 
+	"github.com/porty/ddcli/datadog"
 	"github.com/urfave/cli"
 )
 
@@ -25,7 +28,8 @@ func export(c *cli.Context) error {
 	dashboardDir := path.Join(outputDir, "dashboards")
 	screenboardDir := path.Join(outputDir, "screenboards")
 	monitorsDir := path.Join(outputDir, "monitors")
-	createDirectories(dashboardDir, screenboardDir, monitorsDir)
+	downtimesDir := path.Join(outputDir, "downtimes")
+	createDirectories(dashboardDir, screenboardDir, monitorsDir, downtimesDir)
 
 	dashes, err := dd.GetDashboards()
 	if err != nil {
@@ -43,16 +47,8 @@ func export(c *cli.Context) error {
 				os.Exit(1)
 			}
 			dest := path.Join(dashboardDir, info.ID+".json")
-			b, err := json.MarshalIndent(dash, "", "  ")
-			if err != nil {
-				log.Print("Failed to JSON marshal dashboard: " + err.Error())
-				os.Exit(1)
-			}
-			if b[len(b)-1] != '\n' {
-				b = append(b, '\n')
-			}
-			if err = ioutil.WriteFile(dest, b, 0664); err != nil {
-				log.Printf("Failed to write to file '%s': %s", dest, err.Error())
+			if err := writeJSONFile(dest, dash); err != nil {
+				log.Print("Failed to write dashboard: " + err.Error())
 				os.Exit(1)
 			}
 		}
@@ -74,16 +70,8 @@ func export(c *cli.Context) error {
 				os.Exit(1)
 			}
 			dest := path.Join(screenboardDir, fmt.Sprintf("%d.json", info.ID))
-			b, err := json.MarshalIndent(screenboard, "", "  ")
-			if err != nil {
-				log.Print("Failed to JSON marshal screenboard: " + err.Error())
-				os.Exit(1)
-			}
-			if b[len(b)-1] != '\n' {
-				b = append(b, '\n')
-			}
-			if err = ioutil.WriteFile(dest, b, 0664); err != nil {
-				log.Printf("Failed to write to file '%s': %s", dest, err.Error())
+			if err := writeJSONFile(dest, screenboard); err != nil {
+				log.Print("Failed to write screenboard: " + err.Error())
 				os.Exit(1)
 			}
 		}
@@ -98,20 +86,131 @@ func export(c *cli.Context) error {
 	} else {
 		for _, monitor := range monitors {
 			dest := path.Join(monitorsDir, fmt.Sprintf("%d.json", monitor.ID))
-			b, err := json.MarshalIndent(monitor, "", "  ")
-			if err != nil {
-				log.Print("Failed to JSON marshal monitor: " + err.Error())
+			if err := writeJSONFile(dest, monitor); err != nil {
+				log.Print("Failed to write monitor: " + err.Error())
+				os.Exit(1)
+			}
+		}
+		log.Printf("Exported %d monitors", len(monitors))
+	}
+
+	downtimes, err := dd.GetDowntimes(false)
+	if err != nil {
+		panic(err)
+	}
+	if len(downtimes) == 0 {
+		log.Print("No downtimes")
+	} else {
+		for _, downtime := range downtimes {
+			dest := path.Join(downtimesDir, fmt.Sprintf("%d.json", downtime.ID))
+			if err := writeJSONFile(dest, downtime); err != nil {
+				log.Print("Failed to write downtime: " + err.Error())
 				os.Exit(1)
 			}
-			if b[len(b)-1] != '\n' {
-				b = append(b, '\n')
+		}
+		log.Printf("Exported %d downtimes", len(downtimes))
+	}
+
+	if c.Bool("metrics") {
+		metricsDir := path.Join(outputDir, "metrics")
+		createDirectories(metricsDir)
+
+		metrics, err := dd.GetMetrics(time.Now().Add(-1 * c.Duration("metrics-since")))
+		if err != nil {
+			panic(err)
+		}
+		for i, name := range metrics {
+			log.Printf("Getting metric metadata %d of %d...", i+1, len(metrics))
+			metadata, err := dd.GetMetricMetadata(name)
+			if err != nil {
+				log.Printf("Failed to get metadata of metric %s: %s", name, err.Error())
+				os.Exit(1)
 			}
-			if err = ioutil.WriteFile(dest, b, 0664); err != nil {
-				log.Printf("Failed to write to file '%s': %s", dest, err.Error())
+			dest := path.Join(metricsDir, name+".json")
+			if err := writeJSONFile(dest, metadata); err != nil {
+				log.Print("Failed to write metric metadata: " + err.Error())
 				os.Exit(1)
 			}
 		}
-		log.Printf("Exported %d monitors", len(monitors))
+		log.Printf("Exported metadata of %d metrics", len(metrics))
+	}
+
+	if c.Bool("events") {
+		eventsDir := path.Join(outputDir, "events")
+		createDirectories(eventsDir)
+
+		// events are written to a JSON lines file per UTC day, each rewritten
+		// with the whole day's events apart from today's, which has those so far
+		files := map[string]*os.File{}
+		count := 0
+		end := time.Now()
+		err := getEventsByWindow(dd, utcDayStart(end.Add(-1*c.Duration("events-since"))), end, 24*time.Hour, datadog.EventFilters{}, func(e datadog.Event) error {
+			day := time.Unix(e.DateHappened, 0).UTC().Format("2006-01-02")
+			f, ok := files[day]
+			if !ok {
+				var err error
+				if f, err = os.Create(path.Join(eventsDir, day+".jsonl")); err != nil {
+					return err
+				}
+				files[day] = f
+			}
+			count++
+			return json.NewEncoder(f).Encode(e)
+		})
+		for _, f := range files {
+			if cerr := f.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			log.Print("Failed to export events: " + err.Error())
+			os.Exit(1)
+		}
+		log.Printf("Exported %d events", count)
+	}
+
+	if c.Bool("synthetics") {
+		if err := exportSynthetics(dd, path.Join(outputDir, "synthetics")); err != nil {
+			return err
+		}
+	}
+
+	if c.Bool("slos") {
+		if err := exportSLOs(dd, path.Join(outputDir, "slos")); err != nil {
+			return err
+		}
+	}
+
+	if c.Bool("access") {
+		if err := exportAccess(dd, path.Join(outputDir, "access")); err != nil {
+			return err
+		}
+	}
+
+	if c.Bool("logs") {
+		if err := exportLogsConfig(dd, path.Join(outputDir, "logs")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// utcDayStart returns midnight UTC at the start of t's UTC day.
+func utcDayStart(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// writeJSONFile writes v to dest as indented JSON with a trailing newline.
+func writeJSONFile(dest string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.New("failed to JSON marshal: " + err.Error())
+	}
+	if b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	if err = ioutil.WriteFile(dest, b, 0664); err != nil {
+		return fmt.Errorf("failed to write to file '%s': %s", dest, err.Error())
 	}
 	return nil
 }
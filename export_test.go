@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUTCDayStart(t *testing.T) {
+	sydney := time.FixedZone("AEST", 10*60*60)
+	tests := []struct {
+		t        time.Time
+		expected time.Time
+	}{
+		{time.Date(2026, 10, 19, 11, 30, 0, 0, time.UTC), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 10, 19, 23, 59, 59, 999, time.UTC), time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		// 8am in Sydney is still the previous day in UTC
+		{time.Date(2026, 10, 19, 8, 0, 0, 0, sydney), time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, utcDayStart(test.t), test.t.String())
+	}
+}
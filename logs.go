@@ -0,0 +1,323 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+var logsOutputFlags = []cli.Flag{
+	cli.StringSliceFlag{
+		Name:  "index",
+		Usage: "Log index to search (repeatable, default all)",
+	},
+	cli.StringFlag{
+		Name:  "format, f",
+		Value: "compact",
+		Usage: "Format, one of compact, json (JSON lines) or csv",
+	},
+	cli.StringFlag{
+		Name:  "fields",
+		Value: "timestamp,status,service,host,message",
+		Usage: "Comma separated fields or attribute paths for csv output, e.g. timestamp,@http.status_code",
+	},
+	cli.BoolFlag{
+		Name:  "no-color",
+		Usage: "Don't colour compact output",
+	},
+}
+
+var logsCommand = cli.Command{
+	Name:  "logs",
+	Usage: "log commands",
+	Subcommands: []cli.Command{
+		{
+			Name:      "search",
+			Usage:     "search logs, oldest first",
+			ArgsUsage: "<query>",
+			Action:    searchLogs,
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name:  "from",
+					Value: "15m",
+					Usage: "Start time as a duration ago, e.g. 15m, RFC3339 or a Unix timestamp",
+				},
+				cli.StringFlag{
+					Name:  "to",
+					Usage: "End time as a duration ago, RFC3339 or a Unix timestamp (default now)",
+				},
+				cli.IntFlag{
+					Name:  "limit, l",
+					Value: 1000,
+					Usage: "Maximum number of logs to return, 0 for no limit",
+				},
+			}, logsOutputFlags...),
+		},
+		{
+			Name:      "tail",
+			Usage:     "stream new logs as they arrive",
+			ArgsUsage: "<query>",
+			Action:    tailLogs,
+			Flags: append([]cli.Flag{
+				cli.DurationFlag{
+					Name:  "interval",
+					Value: 5 * time.Second,
+					Usage: "How often to poll for new logs",
+				},
+				cli.DurationFlag{
+					Name:  "lag",
+					Value: 30 * time.Second,
+					Usage: "How far behind now to search, to allow for logs being indexed late",
+				},
+			}, logsOutputFlags...),
+		},
+		{
+			Name:      "export-config",
+			Usage:     "export log pipelines, indexes, archives and custom log metrics",
+			ArgsUsage: "<output dir>",
+			Action:    exportLogsConfigCommand,
+		},
+	},
+}
+
+// logsPageSize is the number of logs to fetch per request.
+const logsPageSize = 1000
+
+func searchLogs(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("query required")
+	}
+	now := time.Now()
+	from, err := parseTimeOrAgo(c.String("from"), now)
+	if err != nil {
+		return errors.New("invalid --from: " + err.Error())
+	}
+	to := now
+	if c.String("to") != "" {
+		if to, err = parseTimeOrAgo(c.String("to"), now); err != nil {
+			return errors.New("invalid --to: " + err.Error())
+		}
+	}
+
+	out, err := newLogWriter(c)
+	if err != nil {
+		return err
+	}
+	defer out.Flush()
+
+	api := getAPI()
+	q := datadog.LogsQuery{
+		Query:   c.Args()[0],
+		From:    from,
+		To:      to,
+		Indexes: c.StringSlice("index"),
+		Limit:   logsPageSize,
+	}
+	limit := c.Int("limit")
+	count := 0
+	cursor := ""
+	for {
+		logs, next, err := api.SearchLogs(q, cursor)
+		if err != nil {
+			return err
+		}
+		for _, l := range logs {
+			if limit > 0 && count >= limit {
+				return nil
+			}
+			if err := out.Write(l); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+			count++
+		}
+		if next == "" {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+func tailLogs(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("query required")
+	}
+
+	out, err := newLogWriter(c)
+	if err != nil {
+		return err
+	}
+
+	api := getAPI()
+	lag := c.Duration("lag")
+	from := time.Now().Add(-1 * lag)
+	// IDs of logs already written at the from timestamp, which the next search includes again
+	seen := map[string]bool{}
+	for {
+		to := time.Now().Add(-1 * lag)
+		q := datadog.LogsQuery{
+			Query:   c.Args()[0],
+			From:    from,
+			To:      to,
+			Indexes: c.StringSlice("index"),
+			Limit:   logsPageSize,
+		}
+		cursor := ""
+		for {
+			logs, next, err := api.SearchLogs(q, cursor)
+			if err != nil {
+				return err
+			}
+			for _, l := range logs {
+				if seen[l.ID] {
+					continue
+				}
+				if l.Timestamp.After(from) {
+					from = l.Timestamp
+					seen = map[string]bool{}
+				}
+				seen[l.ID] = true
+				if err := out.Write(l); err != nil {
+					return errors.New("failed to write output: " + err.Error())
+				}
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		out.Flush()
+		time.Sleep(c.Duration("interval"))
+	}
+}
+
+type logWriter interface {
+	Write(l datadog.Log) error
+	Flush()
+}
+
+func newLogWriter(c *cli.Context) (logWriter, error) {
+	switch c.String("format") {
+	case "json":
+		return jsonLogWriter{json.NewEncoder(os.Stdout)}, nil
+	case "csv":
+		fields := strings.Split(c.String("fields"), ",")
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write(fields); err != nil {
+			return nil, errors.New("failed to write output: " + err.Error())
+		}
+		return csvLogWriter{w, fields}, nil
+	case "compact":
+		colour := !c.Bool("no-color") && os.Getenv("NO_COLOR") == ""
+		if fi, err := os.Stdout.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+			colour = false
+		}
+		return compactLogWriter{colour}, nil
+	}
+	return nil, errors.New("--format must be one of compact, json or csv")
+}
+
+type jsonLogWriter struct {
+	enc *json.Encoder
+}
+
+func (w jsonLogWriter) Write(l datadog.Log) error {
+	return w.enc.Encode(l)
+}
+
+func (w jsonLogWriter) Flush() {}
+
+type csvLogWriter struct {
+	w      *csv.Writer
+	fields []string
+}
+
+func (w csvLogWriter) Write(l datadog.Log) error {
+	row := make([]string, len(w.fields))
+	for i, field := range w.fields {
+		row[i] = logField(l, strings.TrimSpace(field))
+	}
+	return w.w.Write(row)
+}
+
+func (w csvLogWriter) Flush() {
+	w.w.Flush()
+}
+
+// logField returns a log's standard field, or a value from its attributes for
+// a dot separated path optionally starting with @, e.g. @http.status_code.
+func logField(l datadog.Log, field string) string {
+	switch field {
+	case "id":
+		return l.ID
+	case "timestamp":
+		return l.Timestamp.Format(time.RFC3339Nano)
+	case "status":
+		return l.Status
+	case "service":
+		return l.Service
+	case "host":
+		return l.Host
+	case "message":
+		return l.Message
+	case "tags":
+		return strings.Join(l.Tags, " ")
+	}
+
+	var v interface{} = l.Attributes
+	for _, part := range strings.Split(strings.TrimPrefix(field, "@"), ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		v = m[part]
+	}
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type compactLogWriter struct {
+	colour bool
+}
+
+var logStatusColours = map[string]string{
+	"emergency": "\x1b[31m",
+	"alert":     "\x1b[31m",
+	"critical":  "\x1b[31m",
+	"error":     "\x1b[31m",
+	"warn":      "\x1b[33m",
+	"warning":   "\x1b[33m",
+	"info":      "\x1b[32m",
+	"notice":    "\x1b[32m",
+	"debug":     "\x1b[90m",
+}
+
+func (w compactLogWriter) Write(l datadog.Log) error {
+	status := fmt.Sprintf("%-5s", strings.ToUpper(l.Status))
+	if colour, ok := logStatusColours[strings.ToLower(l.Status)]; ok && w.colour {
+		status = colour + status + "\x1b[0m"
+	}
+	source := l.Service + "@" + l.Host
+	if w.colour {
+		source = "\x1b[36m" + source + "\x1b[0m"
+	}
+	_, err := fmt.Printf("%s %s %s %s\n", l.Timestamp.Local().Format("2006-01-02T15:04:05.000"), status, source, strings.Replace(l.Message, "\n", " ", -1))
+	return err
+}
+
+func (w compactLogWriter) Flush() {}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"path"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+func exportLogsConfigCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("output directory required")
+	}
+	return exportLogsConfig(getAPI(), c.Args()[0])
+}
+
+// exportLogsConfig writes log pipelines, indexes, archives and custom log
+// metrics to subfolders of outputDir, one file each, as returned by the API.
+func exportLogsConfig(dd *datadog.API, outputDir string) error {
+	pipelinesDir := path.Join(outputDir, "pipelines")
+	indexesDir := path.Join(outputDir, "indexes")
+	archivesDir := path.Join(outputDir, "archives")
+	metricsDir := path.Join(outputDir, "metrics")
+	createDirectories(pipelinesDir, indexesDir, archivesDir, metricsDir)
+
+	pipelines, err := dd.GetLogsPipelines()
+	if err != nil {
+		return errors.New("failed to get log pipelines: " + err.Error())
+	}
+	for _, pipeline := range pipelines {
+		if err := writeJSONFile(path.Join(pipelinesDir, pipeline.ID+".json"), pipeline.Raw); err != nil {
+			return errors.New("failed to write log pipeline: " + err.Error())
+		}
+	}
+	log.Printf("Exported %d log pipelines", len(pipelines))
+
+	indexes, err := dd.GetLogsIndexes()
+	if err != nil {
+		return errors.New("failed to get log indexes: " + err.Error())
+	}
+	for _, index := range indexes {
+		if err := writeJSONFile(path.Join(indexesDir, index.Name+".json"), index.Raw); err != nil {
+			return errors.New("failed to write log index: " + err.Error())
+		}
+	}
+	log.Printf("Exported %d log indexes", len(indexes))
+
+	archives, err := dd.GetLogsArchives()
+	if err != nil {
+		return errors.New("failed to get log archives: " + err.Error())
+	}
+	for _, archive := range archives {
+		if err := writeJSONFile(path.Join(archivesDir, archive.ID+".json"), archive.Raw); err != nil {
+			return errors.New("failed to write log archive: " + err.Error())
+		}
+	}
+	log.Printf("Exported %d log archives", len(archives))
+
+	metrics, err := dd.GetLogsMetrics()
+	if err != nil {
+		return errors.New("failed to get log metrics: " + err.Error())
+	}
+	for _, metric := range metrics {
+		if err := writeJSONFile(path.Join(metricsDir, metric.ID+".json"), metric.Raw); err != nil {
+			return errors.New("failed to write log metric: " + err.Error())
+		}
+	}
+	log.Printf("Exported %d log metrics", len(metrics))
+	return nil
+}
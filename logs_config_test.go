@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportLogsConfig(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/logs/config/pipelines":
+			fmt.Fprint(w, `[{"id": "p1", "name": "API", "filter": {"query": "service:api"}, "processors": [], "tags": ["team:api"]}]`)
+		case "/api/v1/logs/config/indexes":
+			fmt.Fprint(w, `{"indexes": [{"name": "main", "filter": {"query": "*"}, "num_flex_logs_retention_days": 30}]}`)
+		case "/api/v2/logs/config/archives":
+			fmt.Fprint(w, `{"data": []}`)
+		case "/api/v2/logs/config/metrics":
+			fmt.Fprint(w, `{"data": [{"id": "api.duration", "type": "logs_metrics", "attributes": {"compute": {"aggregation_type": "distribution", "path": "@duration", "include_percentiles": true}}}]}`)
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ddcli-logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, exportLogsConfig(datadog.NewWithBaseURL("api-key", "app-key", server.URL), dir))
+
+	// everything the API returned is kept, not just the fields ddcli models
+	for file, expected := range map[string]string{
+		"pipelines/p1.json":         `{"id": "p1", "name": "API", "filter": {"query": "service:api"}, "processors": [], "tags": ["team:api"]}`,
+		"indexes/main.json":         `{"name": "main", "filter": {"query": "*"}, "num_flex_logs_retention_days": 30}`,
+		"metrics/api.duration.json": `{"id": "api.duration", "type": "logs_metrics", "attributes": {"compute": {"aggregation_type": "distribution", "path": "@duration", "include_percentiles": true}}}`,
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(b), file)
+	}
+}
+
+func TestExportLogsConfigError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ddcli-logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = exportLogsConfig(datadog.NewWithBaseURL("api-key", "app-key", server.URL), dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get log pipelines")
+}
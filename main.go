@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/porty/ddcli/datadog"
 	"github.com/urfave/cli"
@@ -16,6 +17,42 @@ func main() {
 			Name:   "export",
 			Usage:  "export Datadog config",
 			Action: export,
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "metrics",
+					Usage: "Also export the metadata of metrics active within --metrics-since",
+				},
+				cli.DurationFlag{
+					Name:  "metrics-since",
+					Value: 24 * time.Hour,
+					Usage: "Duration to look for active metrics in, e.g. 1h, 2h45m",
+				},
+				cli.BoolFlag{
+					Name:  "events",
+					Usage: "Also export events from within --events-since, a JSON lines file per day",
+				},
+				cli.DurationFlag{
+					Name:  "events-since",
+					Value: 30 * 24 * time.Hour,
+					Usage: "Duration to export events from, e.g. 720h",
+				},
+				cli.BoolFlag{
+					Name:  "logs",
+					Usage: "Also export log pipelines, indexes, archives and custom log metrics",
+				},
+				cli.BoolFlag{
+					Name:  "synthetics",
+					Usage: "Also export synthetics tests and global variables",
+				},
+				cli.BoolFlag{
+					Name:  "slos",
+					Usage: "Also export SLOs",
+				},
+				cli.BoolFlag{
+					Name:  "access",
+					Usage: "Also export users, roles and teams for access reviews, which includes names and email addresses",
+				},
+			},
 		},
 		{
 			Name:  "metrics",
@@ -30,6 +67,31 @@ func main() {
 							Name:  "duration, d",
 							Usage: "Duration, e.g. 1hr, 2h45m",
 						},
+						cli.StringFlag{
+							Name:  "prefix, p",
+							Usage: "Only list metrics starting with this prefix",
+						},
+						cli.StringFlag{
+							Name:  "regex, r",
+							Usage: "Only list metrics matching this regular expression",
+						},
+						cli.BoolFlag{
+							Name:  "tree",
+							Usage: "Group metrics by their dot separated parts, with counts",
+						},
+						cli.IntFlag{
+							Name:  "depth",
+							Usage: "Maximum depth of --tree output (default unlimited)",
+						},
+						cli.BoolFlag{
+							Name:  "count-only, c",
+							Usage: "Only print the number of matching metrics",
+						},
+						cli.StringFlag{
+							Name:  "format, f",
+							Value: "text",
+							Usage: "Format, one of text, csv, md (markdown) or json",
+						},
 					},
 				},
 				{
@@ -42,10 +104,247 @@ func main() {
 							Value: "csv",
 							Usage: "Format, either csv or md (markdown)",
 						},
+						cli.StringFlag{
+							Name:  "month, m",
+							Usage: "Month to list, e.g. 2026-09 (default this month)",
+						},
+						cli.IntFlag{
+							Name:  "months",
+							Value: 1,
+							Usage: "Number of months up to --month to show as a trend",
+						},
+					},
+				},
+				{
+					Name:   "cost",
+					Usage:  "estimate the monthly cost of the top 500 custom metrics",
+					Action: metricsCost,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "format, f",
+							Value: "csv",
+							Usage: "Format, either csv, md (markdown) or json",
+						},
+						cli.StringFlag{
+							Name:  "month, m",
+							Usage: "Month to report on, e.g. 2026-09 (default this month)",
+						},
+						cli.Float64Flag{
+							Name:  "price",
+							Value: 5,
+							Usage: "Price per 100 custom metrics per month",
+						},
+						cli.IntFlag{
+							Name:  "allotment-per-host",
+							Value: 100,
+							Usage: "Custom metrics included per host",
+						},
+						cli.IntFlag{
+							Name:  "hosts",
+							Usage: "Number of billable hosts, required",
+						},
+						cli.StringFlag{
+							Name:  "group-by, g",
+							Value: "metric",
+							Usage: "Group costs by metric, category or prefix",
+						},
+						cli.IntFlag{
+							Name:  "prefix-depth",
+							Value: 1,
+							Usage: "Number of name parts to group by with --group-by prefix",
+						},
+					},
+				},
+				{
+					Name:      "describe",
+					Usage:     "show a metric's type, unit and description",
+					ArgsUsage: "<metric>",
+					Action:    describeMetric,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "format, f",
+							Value: "text",
+							Usage: "Format, either text or json",
+						},
+					},
+				},
+				{
+					Name:      "set-metadata",
+					Usage:     "change a metric's type, unit or description",
+					ArgsUsage: "<metric>",
+					Action:    setMetricMetadata,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "type",
+							Usage: "Metric type, e.g. gauge, rate, count or distribution",
+						},
+						cli.StringFlag{
+							Name:  "unit",
+							Usage: "Unit, e.g. byte or request",
+						},
+						cli.StringFlag{
+							Name:  "per-unit",
+							Usage: "Unit the metric is per, e.g. second for bytes per second",
+						},
+						cli.StringFlag{
+							Name:  "description",
+							Usage: "Description of the metric",
+						},
+						cli.StringFlag{
+							Name:  "short-name",
+							Usage: "Short name to show in graphs",
+						},
+						cli.IntFlag{
+							Name:  "statsd-interval",
+							Usage: "StatsD flush interval in seconds",
+						},
+					},
+				},
+				{
+					Name:      "query",
+					Usage:     "get the timeseries data for a metric query",
+					ArgsUsage: "<query>",
+					Action:    queryMetrics,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "from",
+							Value: "1h",
+							Usage: "Start time as a duration ago, e.g. 2h, RFC3339 or a Unix timestamp",
+						},
+						cli.StringFlag{
+							Name:  "to",
+							Usage: "End time as a duration ago, RFC3339 or a Unix timestamp (default now)",
+						},
+						cli.StringFlag{
+							Name:  "format, f",
+							Value: "csv",
+							Usage: "Format, either csv, md (markdown) or json",
+						},
+						cli.BoolFlag{
+							Name:  "ascii-chart",
+							Usage: "Draw a sparkline of each series instead",
+						},
+						cli.IntFlag{
+							Name:  "width",
+							Value: 60,
+							Usage: "Maximum width of --ascii-chart sparklines",
+						},
+					},
+				},
+				{
+					Name:      "send",
+					Usage:     "submit a metric point, or points read from stdin as JSON or DogStatsD lines if none is given",
+					ArgsUsage: "[<metric> <value>]",
+					Action:    sendMetric,
+					Flags: []cli.Flag{
+						cli.StringSliceFlag{
+							Name:  "tags, t",
+							Usage: "Tag to add to the point (repeatable)",
+						},
+						cli.StringFlag{
+							Name:  "type",
+							Usage: "Metric type, one of gauge, count or rate",
+						},
+						cli.IntFlag{
+							Name:  "interval",
+							Usage: "Interval in seconds for count and rate metrics",
+						},
+						cli.StringFlag{
+							Name:  "host",
+							Usage: "Host to submit the point for",
+						},
+						cli.StringFlag{
+							Name:  "timestamp",
+							Usage: "Time of the point as RFC3339 or a Unix timestamp (default now)",
+						},
+					},
+				},
+				{
+					Name:      "tags",
+					Usage:     "list a metric's tag keys by their number of distinct values",
+					ArgsUsage: "<metric>",
+					Action:    metricTags,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "format, f",
+							Value: "csv",
+							Usage: "Format, either csv, md (markdown) or json",
+						},
+					},
+				},
+				{
+					Name:   "cardinality",
+					Usage:  "rank active metrics by their indexed tag volume",
+					Action: metricsCardinality,
+					Flags: []cli.Flag{
+						cli.IntFlag{
+							Name:  "top",
+							Value: 50,
+							Usage: "Number of metrics to list, 0 for all",
+						},
+						cli.StringFlag{
+							Name:  "prefix, p",
+							Usage: "Only check metrics starting with this prefix",
+						},
+						cli.DurationFlag{
+							Name:  "duration, d",
+							Value: 24 * time.Hour,
+							Usage: "Check metrics active within this duration, e.g. 1h, 2h45m",
+						},
+						cli.StringFlag{
+							Name:  "format, f",
+							Value: "csv",
+							Usage: "Format, either csv, md (markdown) or json",
+						},
+					},
+				},
+				{
+					Name:      "usage",
+					Usage:     "list the dashboards, screenboards and monitors that use a metric",
+					ArgsUsage: "<metric>",
+					Action:    metricUsage,
+					Flags: []cli.Flag{
+						cli.StringFlag{
+							Name:  "format, f",
+							Value: "csv",
+							Usage: "Format, either csv or md (markdown)",
+						},
+					},
+				},
+				{
+					Name:   "orphaned",
+					Usage:  "list metrics that no dashboard, screenboard or monitor uses",
+					Action: orphanedMetrics,
+					Flags: []cli.Flag{
+						cli.DurationFlag{
+							Name:  "duration, d",
+							Value: 24 * time.Hour,
+							Usage: "Include metrics active within this duration, e.g. 1h, 2h45m",
+						},
+						cli.BoolFlag{
+							Name:  "custom-only",
+							Usage: "Only check the top 500 custom metrics for the month",
+						},
 					},
 				},
 			},
 		},
+		downtimeCommand,
+		monitorsCommand,
+		queryCommand,
+		eventsCommand,
+		checkCommand,
+		logsCommand,
+		syntheticsCommand,
+		sloCommand,
+		usersCommand,
+		rolesCommand,
+		reportCommand,
+		renderCommand,
+		planCommand,
+		applyCommand,
+		dashboardsCommand,
+		refactorCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
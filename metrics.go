@@ -4,25 +4,152 @@ import (
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"log"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/porty/ddcli/datadog"
 	"github.com/porty/ddcli/markdown"
 	"github.com/urfave/cli"
 )
 
 func activeMetricsFromDuration(c *cli.Context) error {
+	var re *regexp.Regexp
+	if c.String("regex") != "" {
+		var err error
+		if re, err = regexp.Compile(c.String("regex")); err != nil {
+			return errors.New("invalid regex: " + err.Error())
+		}
+	}
+
 	api := getAPI()
 	dur := c.Duration("duration")
 	t := time.Now().Add(-1 * dur)
 
-	metrics, err := api.GetMetrics(t)
+	all, err := api.GetMetrics(t)
 	if err != nil {
 		return err
 	}
+	metrics := []string{}
+	for _, metric := range all {
+		if strings.HasPrefix(metric, c.String("prefix")) && (re == nil || re.MatchString(metric)) {
+			metrics = append(metrics, metric)
+		}
+	}
+	sort.Strings(metrics)
+
+	if c.Bool("count-only") {
+		fmt.Println(len(metrics))
+		return nil
+	}
+
+	format := c.String("format")
+	if c.Bool("tree") {
+		root := newMetricTree(metrics)
+		switch format {
+		case "json":
+			return printJSON(root.Children)
+		case "csv", "md":
+			w := newColumnWriter(format)
+			if err := w.Write([]string{"Prefix", "Metrics"}); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+			if err := root.writeRows(w, "", c.Int("depth")); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+			w.Flush()
+		default:
+			root.print("", c.Int("depth"))
+		}
+		return nil
+	}
+
+	switch format {
+	case "json":
+		return printJSON(metrics)
+	case "csv", "md":
+		w := newColumnWriter(format)
+		if err := w.Write([]string{"Name"}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+		for _, metric := range metrics {
+			if err := w.Write([]string{metric}); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+		}
+		w.Flush()
+	default:
+		for _, metric := range metrics {
+			fmt.Println(metric)
+		}
+	}
+	return nil
+}
+
+// metricTree groups metric names by their dot separated parts.
+type metricTree struct {
+	Name     string        `json:"name"`
+	Count    int           `json:"count"`
+	Children []*metricTree `json:"children,omitempty"`
+
+	index map[string]*metricTree
+}
+
+// newMetricTree builds a tree from metric names, keeping their order.
+func newMetricTree(metrics []string) *metricTree {
+	root := &metricTree{}
 	for _, metric := range metrics {
-		fmt.Println(metric)
+		node := root
+		node.Count++
+		for _, part := range strings.Split(metric, ".") {
+			child, ok := node.index[part]
+			if !ok {
+				child = &metricTree{Name: part}
+				if node.index == nil {
+					node.index = map[string]*metricTree{}
+				}
+				node.index[part] = child
+				node.Children = append(node.Children, child)
+			}
+			child.Count++
+			node = child
+		}
+	}
+	return root
+}
+
+// print writes the children of t, indented by their depth, stopping at
+// maxDepth if it is greater than 0.
+func (t *metricTree) print(indent string, maxDepth int) {
+	for _, child := range t.Children {
+		if len(child.Children) == 0 {
+			fmt.Println(indent + child.Name)
+		} else {
+			fmt.Printf("%s%s (%d)\n", indent, child.Name, child.Count)
+		}
+		if maxDepth != 1 {
+			child.print(indent+"  ", maxDepth-1)
+		}
+	}
+}
+
+// writeRows writes a row for every prefix below t, stopping at maxDepth if it
+// is greater than 0.
+func (t *metricTree) writeRows(w columnWriter, prefix string, maxDepth int) error {
+	for _, child := range t.Children {
+		name := prefix + child.Name
+		if err := w.Write([]string{name, strconv.Itoa(child.Count)}); err != nil {
+			return err
+		}
+		if maxDepth != 1 {
+			if err := child.writeRows(w, name+".", maxDepth-1); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
@@ -32,20 +159,41 @@ type columnWriter interface {
 	Flush()
 }
 
+// newColumnWriter returns a markdown table writer for format "md", otherwise
+// a CSV writer, writing to stdout.
+func newColumnWriter(format string) columnWriter {
+	if format == "md" {
+		return markdown.NewTableWriter(os.Stdout)
+	}
+	return csv.NewWriter(os.Stdout)
+}
+
 func top500CustomMetrics(c *cli.Context) error {
+	month := time.Now()
+	if c.String("month") != "" {
+		t, err := time.Parse("2006-01", c.String("month"))
+		if err != nil {
+			return errors.New("invalid month, expected e.g. 2026-09: " + c.String("month"))
+		}
+		month = t
+	}
+	months := c.Int("months")
+	if months < 1 {
+		return errors.New("--months must be at least 1")
+	}
+
 	api := getAPI()
 
-	metrics, err := api.GetTopAverageMetrics()
+	if months > 1 {
+		return top500CustomMetricsTrend(api, month, months, c.String("format"))
+	}
+
+	metrics, err := api.GetTopAverageMetricsForMonth(month)
 	if err != nil {
 		return err
 	}
 
-	var w columnWriter
-	if c.String("format") == "md" {
-		w = markdown.NewTableWriter(os.Stdout)
-	} else {
-		w = csv.NewWriter(os.Stdout)
-	}
+	w := newColumnWriter(c.String("format"))
 	if err := w.Write([]string{"Name", "Average per hour", "Max per hour"}); err != nil {
 		return errors.New("failed to write output: " + err.Error())
 	}
@@ -63,3 +211,91 @@ func top500CustomMetrics(c *cli.Context) error {
 	w.Flush()
 	return nil
 }
+
+// top500CustomMetricsTrend writes a table of each metric's usage over the
+// given number of months ending with last, along with how much its average
+// per hour changed each month and over the period.
+func top500CustomMetricsTrend(api *datadog.API, last time.Time, months int, format string) error {
+	first := time.Date(last.Year(), last.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC)
+
+	// usage[name][i] is the usage of metric name in month i
+	usage := map[string][]*datadog.MetricsUsage{}
+	// names are ordered by the most recent month they appear in, then by that month's ranking
+	names := []string{}
+	header := []string{"Name"}
+	for i := 0; i < months; i++ {
+		month := first.AddDate(0, i, 0).Format("2006-01")
+		header = append(header, month+" avg", month+" max")
+		if i > 0 {
+			header = append(header, month+" change")
+		}
+	}
+	header = append(header, "Avg delta", "Growth %")
+
+	for i := months - 1; i >= 0; i-- {
+		month := first.AddDate(0, i, 0)
+		log.Printf("Getting top 500 custom metrics for %s...", month.Format("2006-01"))
+		metrics, err := api.GetTopAverageMetricsForMonth(month)
+		if err != nil {
+			return err
+		}
+		for j := range metrics {
+			m := metrics[j]
+			if usage[m.Name] == nil {
+				usage[m.Name] = make([]*datadog.MetricsUsage, months)
+				names = append(names, m.Name)
+			}
+			usage[m.Name][i] = &m
+		}
+	}
+
+	w := newColumnWriter(format)
+	if err := w.Write(header); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, name := range names {
+		row := trendRow(name, usage[name])
+		if err := w.Write(row); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+// trendRow returns a metric's row of the trend table: each month's average and
+// max per hour, the change in average from the month before, then the change
+// from the first month the metric appears in to the final month. Months the
+// metric isn't in the top 500 are left blank.
+func trendRow(name string, usage []*datadog.MetricsUsage) []string {
+	row := []string{name}
+	first := -1
+	for i, m := range usage {
+		if m == nil {
+			row = append(row, "", "")
+		} else {
+			row = append(row, strconv.Itoa(m.AvgPerHour), strconv.Itoa(m.MaxPerHour))
+			if first < 0 {
+				first = i
+			}
+		}
+		if i > 0 {
+			change := ""
+			if m != nil && usage[i-1] != nil {
+				change = fmt.Sprintf("%+d", m.AvgPerHour-usage[i-1].AvgPerHour)
+			}
+			row = append(row, change)
+		}
+	}
+
+	delta, growth := "", ""
+	last := len(usage) - 1
+	if first >= 0 && first < last && usage[last] != nil {
+		firstAvg, lastAvg := usage[first].AvgPerHour, usage[last].AvgPerHour
+		delta = fmt.Sprintf("%+d", lastAvg-firstAvg)
+		if firstAvg != 0 {
+			growth = fmt.Sprintf("%+.1f", float64(lastAvg-firstAvg)/float64(firstAvg)*100)
+		}
+	}
+	return append(row, delta, growth)
+}
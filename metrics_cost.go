@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+type metricCost struct {
+	Name          string  `json:"name"`
+	Metrics       int     `json:"metrics"`
+	AvgPerHour    int     `json:"avg_per_hour"`
+	MaxPerHour    int     `json:"max_per_hour"`
+	EstimatedCost float64 `json:"estimated_cost"`
+	Percentage    float64 `json:"percentage"`
+}
+
+func metricsCost(c *cli.Context) error {
+	// without hosts there's no allotment, and every metric would be billed
+	if c.Int("hosts") < 1 {
+		return errors.New("--hosts is required, the number of billable hosts")
+	}
+	month := time.Now()
+	if c.String("month") != "" {
+		t, err := time.Parse("2006-01", c.String("month"))
+		if err != nil {
+			return errors.New("invalid month, expected e.g. 2026-09: " + c.String("month"))
+		}
+		month = t
+	}
+
+	var groupName func(m datadog.MetricsUsage) string
+	switch c.String("group-by") {
+	case "metric":
+		groupName = func(m datadog.MetricsUsage) string { return m.Name }
+	case "category":
+		groupName = func(m datadog.MetricsUsage) string { return m.Category }
+	case "prefix":
+		depth := c.Int("prefix-depth")
+		if depth < 1 {
+			return errors.New("--prefix-depth must be at least 1")
+		}
+		groupName = func(m datadog.MetricsUsage) string { return metricPrefix(m.Name, depth) }
+	default:
+		return errors.New("--group-by must be one of metric, category or prefix")
+	}
+
+	api := getAPI()
+	usage, err := api.GetTopAverageMetricsForMonth(month)
+	if err != nil {
+		return err
+	}
+
+	costs := estimateMetricCosts(usage, groupName, c.Float64("price"), c.Int("allotment-per-host")*c.Int("hosts"))
+
+	if c.String("format") == "json" {
+		return printJSON(costs)
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Name", "Metrics", "Average per hour", "Max per hour", "Estimated monthly cost", "% of total"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, cost := range costs {
+		if err := w.Write([]string{
+			cost.Name,
+			strconv.Itoa(cost.Metrics),
+			strconv.Itoa(cost.AvgPerHour),
+			strconv.Itoa(cost.MaxPerHour),
+			fmt.Sprintf("%.2f", cost.EstimatedCost),
+			fmt.Sprintf("%.1f", cost.Percentage),
+		}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+// estimateMetricCosts groups usage with groupName and estimates each group's
+// monthly cost, ranked from most to least expensive.
+//
+// Datadog bills custom metrics over the allotment at price per 100 metrics,
+// based on the average number of metrics per hour. Each group is charged its
+// share of that overage in proportion to its average per hour.
+func estimateMetricCosts(usage []datadog.MetricsUsage, groupName func(datadog.MetricsUsage) string, price float64, allotment int) []metricCost {
+	groups := map[string]*metricCost{}
+	total := 0
+	for _, m := range usage {
+		name := groupName(m)
+		g, ok := groups[name]
+		if !ok {
+			g = &metricCost{Name: name}
+			groups[name] = g
+		}
+		g.Metrics++
+		g.AvgPerHour += m.AvgPerHour
+		g.MaxPerHour += m.MaxPerHour
+		total += m.AvgPerHour
+	}
+
+	billable := total - allotment
+	if billable < 0 {
+		billable = 0
+	}
+	totalCost := float64(billable) / 100 * price
+	log.Printf("%d custom metrics per hour on average, %d over the allotment of %d, estimated monthly cost %.2f", total, billable, allotment, totalCost)
+
+	costs := []metricCost{}
+	for _, g := range groups {
+		if total > 0 {
+			g.Percentage = float64(g.AvgPerHour) / float64(total) * 100
+			g.EstimatedCost = totalCost * float64(g.AvgPerHour) / float64(total)
+		}
+		costs = append(costs, *g)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].AvgPerHour != costs[j].AvgPerHour {
+			return costs[i].AvgPerHour > costs[j].AvgPerHour
+		}
+		return costs[i].Name < costs[j].Name
+	})
+	return costs
+}
+
+// metricPrefix returns the first depth dot separated parts of name followed
+// by .*, e.g. myservice.* for myservice.requests.count with a depth of 1.
+func metricPrefix(name string, depth int) string {
+	parts := strings.Split(name, ".")
+	if len(parts) <= depth {
+		return name
+	}
+	return strings.Join(parts[:depth], ".") + ".*"
+}
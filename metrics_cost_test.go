@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateMetricCosts(t *testing.T) {
+	usage := []datadog.MetricsUsage{
+		{Name: "a.requests", AvgPerHour: 300, MaxPerHour: 400},
+		{Name: "a.errors", AvgPerHour: 100, MaxPerHour: 150},
+		{Name: "b.latency", AvgPerHour: 400, MaxPerHour: 500},
+	}
+	byPrefix := func(m datadog.MetricsUsage) string { return metricPrefix(m.Name, 1) }
+
+	tests := []struct {
+		name      string
+		allotment int
+		// expected costs of a.* and b.*
+		expected [2]float64
+	}{
+		{"under the allotment", 1000, [2]float64{0, 0}},
+		{"at the allotment", 800, [2]float64{0, 0}},
+		{"one over the allotment", 799, [2]float64{0.025, 0.025}},
+		{"100 over the allotment", 700, [2]float64{2.5, 2.5}},
+		{"no allotment", 0, [2]float64{20, 20}},
+	}
+	for _, test := range tests {
+		costs := estimateMetricCosts(usage, byPrefix, 5, test.allotment)
+		require.Len(t, costs, 2, test.name)
+		// ties in average per hour are ranked by name
+		require.Equal(t, "a.*", costs[0].Name, test.name)
+		require.Equal(t, "b.*", costs[1].Name, test.name)
+		require.InDelta(t, test.expected[0], costs[0].EstimatedCost, 0.0001, test.name)
+		require.InDelta(t, test.expected[1], costs[1].EstimatedCost, 0.0001, test.name)
+	}
+
+	costs := estimateMetricCosts(usage, func(m datadog.MetricsUsage) string { return m.Name }, 5, 0)
+	require.Equal(t, []metricCost{
+		{Name: "b.latency", Metrics: 1, AvgPerHour: 400, MaxPerHour: 500, EstimatedCost: 20, Percentage: 50},
+		{Name: "a.requests", Metrics: 1, AvgPerHour: 300, MaxPerHour: 400, EstimatedCost: 15, Percentage: 37.5},
+		{Name: "a.errors", Metrics: 1, AvgPerHour: 100, MaxPerHour: 150, EstimatedCost: 5, Percentage: 12.5},
+	}, costs)
+
+	prefixes := estimateMetricCosts(usage, byPrefix, 5, 0)
+	require.Equal(t, metricCost{Name: "a.*", Metrics: 2, AvgPerHour: 400, MaxPerHour: 550, EstimatedCost: 20, Percentage: 50}, prefixes[0])
+
+	require.Empty(t, estimateMetricCosts(nil, byPrefix, 5, 100))
+}
+
+func TestMetricPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		depth    int
+		expected string
+	}{
+		{"myservice.requests.count", 1, "myservice.*"},
+		{"myservice.requests.count", 2, "myservice.requests.*"},
+		{"myservice.requests.count", 3, "myservice.requests.count"},
+		{"myservice.requests.count", 4, "myservice.requests.count"},
+		{"myservice", 1, "myservice"},
+		{"a..b", 2, "a..*"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, metricPrefix(test.name, test.depth), "%s with depth %d", test.name, test.depth)
+	}
+}
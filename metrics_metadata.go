@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+func describeMetric(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("metric name required")
+	}
+
+	api := getAPI()
+	metadata, err := api.GetMetricMetadata(c.Args()[0])
+	if err != nil {
+		return err
+	}
+
+	if c.String("format") == "json" {
+		return printJSON(metadata)
+	}
+
+	statsdInterval := ""
+	if metadata.StatsdInterval != 0 {
+		statsdInterval = strconv.Itoa(metadata.StatsdInterval)
+	}
+	for _, field := range [][2]string{
+		{"Name", c.Args()[0]},
+		{"Type", metadata.Type},
+		{"Unit", metadata.Unit},
+		{"Per unit", metadata.PerUnit},
+		{"Short name", metadata.ShortName},
+		{"Description", metadata.Description},
+		{"StatsD interval", statsdInterval},
+		{"Integration", metadata.Integration},
+	} {
+		fmt.Printf("%-16s %s\n", field[0]+":", field[1])
+	}
+	return nil
+}
+
+func setMetricMetadata(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("metric name required")
+	}
+
+	metadata := datadog.MetricMetadata{
+		Type:           c.String("type"),
+		Description:    c.String("description"),
+		ShortName:      c.String("short-name"),
+		Unit:           c.String("unit"),
+		PerUnit:        c.String("per-unit"),
+		StatsdInterval: c.Int("statsd-interval"),
+	}
+	if metadata == (datadog.MetricMetadata{}) {
+		return errors.New("nothing to set, use --type, --unit, --per-unit, --description, --short-name or --statsd-interval")
+	}
+
+	api := getAPI()
+	updated, err := api.UpdateMetricMetadata(c.Args()[0], metadata)
+	if err != nil {
+		return err
+	}
+	return printJSON(updated)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+func queryMetrics(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("query required")
+	}
+
+	now := time.Now()
+	from, err := parseTimeOrAgo(c.String("from"), now)
+	if err != nil {
+		return errors.New("invalid --from: " + err.Error())
+	}
+	to := now
+	if c.String("to") != "" {
+		if to, err = parseTimeOrAgo(c.String("to"), now); err != nil {
+			return errors.New("invalid --to: " + err.Error())
+		}
+	}
+
+	api := getAPI()
+	series, err := api.QueryMetrics(c.Args()[0], from, to)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("ascii-chart") {
+		for _, s := range series {
+			printSparkline(s, c.Int("width"))
+		}
+		return nil
+	}
+
+	if c.String("format") == "json" {
+		return printJSON(series)
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Series", "Time", "Value"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, s := range series {
+		for _, p := range s.Points {
+			value := ""
+			if v, ok := p.Value(); ok {
+				value = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			if err := w.Write([]string{s.Expression, p.Time().Format(time.RFC3339), value}); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+// parseTimeOrAgo parses s as a duration before now, e.g. 2h, or otherwise as
+// an RFC3339 time or Unix timestamp.
+func parseTimeOrAgo(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-1 * d), nil
+	}
+	return parseTime(s)
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// printSparkline prints a series' name, range and a sparkline of its values
+// squeezed into at most width characters.
+func printSparkline(s datadog.Series, width int) {
+	values := []float64{}
+	for _, p := range s.Points {
+		v, ok := p.Value()
+		if !ok {
+			v = math.NaN()
+		}
+		values = append(values, v)
+	}
+
+	// average values into buckets if there are more points than columns
+	if width > 0 && len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			start, end := i*len(values)/width, (i+1)*len(values)/width
+			sum, n := 0.0, 0
+			for _, v := range values[start:end] {
+				if !math.IsNaN(v) {
+					sum += v
+					n++
+				}
+			}
+			buckets[i] = math.NaN()
+			if n > 0 {
+				buckets[i] = sum / float64(n)
+			}
+		}
+		values = buckets
+	}
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case max == min:
+			b.WriteRune(sparks[0])
+		default:
+			b.WriteRune(sparks[int((v-min)/(max-min)*float64(len(sparks)-1)+0.5)])
+		}
+	}
+
+	fmt.Println(s.Expression)
+	if math.IsInf(min, 1) {
+		fmt.Println("  no data")
+		return
+	}
+	fmt.Printf("  %s  min %s max %s\n", b.String(), strconv.FormatFloat(min, 'g', 6, 64), strconv.FormatFloat(max, 'g', 6, 64))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/dogstatsd"
+	"github.com/urfave/cli"
+)
+
+// seriesBatchSize is the number of series to submit per request when reading
+// from stdin.
+const seriesBatchSize = 500
+
+// metricLine is a metric read from stdin in JSON form.
+type metricLine struct {
+	Metric    string   `json:"metric"`
+	Value     *float64 `json:"value"`
+	Type      string   `json:"type"`
+	Host      string   `json:"host"`
+	Tags      []string `json:"tags"`
+	Timestamp int64    `json:"timestamp"`
+}
+
+func sendMetric(c *cli.Context) error {
+	api := getAPI()
+
+	if c.NArg() == 0 {
+		return sendMetricsFromStdin(api)
+	}
+	if c.NArg() != 2 {
+		return errors.New("metric name and value required, or none to read from stdin")
+	}
+	value, err := strconv.ParseFloat(c.Args()[1], 64)
+	if err != nil {
+		return errors.New("invalid value: " + c.Args()[1])
+	}
+	timestamp := time.Now()
+	if c.String("timestamp") != "" {
+		if timestamp, err = parseTime(c.String("timestamp")); err != nil {
+			return errors.New("invalid timestamp: " + err.Error())
+		}
+	}
+
+	return api.PostSeries([]datadog.SubmittedSeries{
+		{
+			Metric:   c.Args()[0],
+			Points:   [][2]float64{{float64(timestamp.Unix()), value}},
+			Type:     c.String("type"),
+			Interval: c.Int("interval"),
+			Host:     c.String("host"),
+			Tags:     c.StringSlice("tags"),
+		},
+	})
+}
+
+// sendMetricsFromStdin submits metrics read from stdin, one per line, either
+// as JSON objects or in DogStatsD format.
+func sendMetricsFromStdin(api *datadog.API) error {
+	batch := []datadog.SubmittedSeries{}
+	sent := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := api.PostSeries(batch); err != nil {
+			return err
+		}
+		sent += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var series datadog.SubmittedSeries
+		if strings.HasPrefix(line, "{") {
+			var m metricLine
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				return fmt.Errorf("line %d: invalid JSON: %s", lineNumber, err.Error())
+			}
+			if m.Metric == "" || m.Value == nil {
+				return fmt.Errorf("line %d: metric and value required", lineNumber)
+			}
+			if m.Timestamp == 0 {
+				m.Timestamp = time.Now().Unix()
+			}
+			series = datadog.SubmittedSeries{
+				Metric: m.Metric,
+				Points: [][2]float64{{float64(m.Timestamp), *m.Value}},
+				Type:   m.Type,
+				Host:   m.Host,
+				Tags:   m.Tags,
+			}
+		} else {
+			var err error
+			if series, err = dogstatsd.ParseMetric(line, time.Now()); err != nil {
+				return fmt.Errorf("line %d: %s", lineNumber, err.Error())
+			}
+		}
+
+		batch = append(batch, series)
+		if len(batch) >= seriesBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.New("failed to read stdin: " + err.Error())
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	log.Printf("Sent %d metrics", sent)
+	return nil
+}
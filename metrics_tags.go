@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+type tagKeyCount struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+func metricTags(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("metric name required")
+	}
+
+	api := getAPI()
+	tags, err := api.GetMetricTags(c.Args()[0])
+	if err != nil {
+		return err
+	}
+
+	byKey := map[string]*tagKeyCount{}
+	keys := []*tagKeyCount{}
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, ":", 2)
+		k, ok := byKey[parts[0]]
+		if !ok {
+			k = &tagKeyCount{Key: parts[0], Values: []string{}}
+			byKey[parts[0]] = k
+			keys = append(keys, k)
+		}
+		if len(parts) == 2 {
+			k.Values = append(k.Values, parts[1])
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i].Values) != len(keys[j].Values) {
+			return len(keys[i].Values) > len(keys[j].Values)
+		}
+		return keys[i].Key < keys[j].Key
+	})
+
+	if c.String("format") == "json" {
+		return printJSON(keys)
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Tag key", "Distinct values", "Examples"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, k := range keys {
+		examples := k.Values
+		if len(examples) > 3 {
+			examples = examples[:3]
+		}
+		if err := w.Write([]string{k.Key, strconv.Itoa(len(k.Values)), strings.Join(examples, " ")}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+type metricCardinality struct {
+	Name           string `json:"name"`
+	IndexedVolume  int64  `json:"indexed_volume"`
+	IngestedVolume int64  `json:"ingested_volume"`
+}
+
+func metricsCardinality(c *cli.Context) error {
+	api := getAPI()
+
+	all, err := api.GetMetrics(time.Now().Add(-1 * c.Duration("duration")))
+	if err != nil {
+		return err
+	}
+	metrics := []string{}
+	for _, metric := range all {
+		if strings.HasPrefix(metric, c.String("prefix")) {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	results := []metricCardinality{}
+	for i, name := range metrics {
+		log.Printf("Getting volumes of metric %d of %d...", i+1, len(metrics))
+		volumes, err := api.GetMetricVolumes(name)
+		if err != nil {
+			return errors.New("failed to get volumes of metric " + name + ": " + err.Error())
+		}
+		indexed := volumes.IndexedVolume
+		if volumes.DistinctVolume > indexed {
+			indexed = volumes.DistinctVolume
+		}
+		results = append(results, metricCardinality{
+			Name:           name,
+			IndexedVolume:  indexed,
+			IngestedVolume: volumes.IngestedVolume,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].IndexedVolume != results[j].IndexedVolume {
+			return results[i].IndexedVolume > results[j].IndexedVolume
+		}
+		return results[i].Name < results[j].Name
+	})
+	if top := c.Int("top"); top > 0 && len(results) > top {
+		results = results[:top]
+	}
+
+	if c.String("format") == "json" {
+		return printJSON(results)
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Name", "Indexed volume", "Ingested volume"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, r := range results {
+		if err := w.Write([]string{
+			r.Name,
+			strconv.FormatInt(r.IndexedVolume, 10),
+			strconv.FormatInt(r.IngestedVolume, 10),
+		}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrendRow(t *testing.T) {
+	usage := func(avg int) *datadog.MetricsUsage {
+		return &datadog.MetricsUsage{AvgPerHour: avg, MaxPerHour: avg * 2}
+	}
+	tests := []struct {
+		name     string
+		usage    []*datadog.MetricsUsage
+		expected []string
+	}{
+		{
+			name:     "every month",
+			usage:    []*datadog.MetricsUsage{usage(100), usage(150), usage(120)},
+			expected: []string{"m", "100", "200", "150", "300", "+50", "120", "240", "-30", "+20", "+20.0"},
+		},
+		{
+			name:     "same first and final value",
+			usage:    []*datadog.MetricsUsage{usage(100), usage(300), usage(100)},
+			expected: []string{"m", "100", "200", "300", "600", "+200", "100", "200", "-200", "+0", "+0.0"},
+		},
+		{
+			name:     "missing from the first month",
+			usage:    []*datadog.MetricsUsage{nil, usage(10), usage(40)},
+			expected: []string{"m", "", "", "10", "20", "", "40", "80", "+30", "+30", "+300.0"},
+		},
+		{
+			// dropping out of the top 500 isn't a change that can be measured
+			name:     "missing from the final month",
+			usage:    []*datadog.MetricsUsage{usage(100), usage(50), nil},
+			expected: []string{"m", "100", "200", "50", "100", "-50", "", "", "", "", ""},
+		},
+		{
+			name:     "only in the final month",
+			usage:    []*datadog.MetricsUsage{nil, nil, usage(5)},
+			expected: []string{"m", "", "", "", "", "", "5", "10", "", "", ""},
+		},
+		{
+			name:     "zero first month",
+			usage:    []*datadog.MetricsUsage{usage(0), usage(5)},
+			expected: []string{"m", "0", "0", "5", "10", "+5", "+5", ""},
+		},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, trendRow("m", test.usage), test.name)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/datadog/query"
+	"github.com/urfave/cli"
+)
+
+// queryReference is a query found in a dashboard, screenboard or monitor.
+type queryReference struct {
+	Kind  string
+	ID    string
+	Title string
+	Query string
+	// Metrics holds the metric names used by Query. It is nil if Query
+	// couldn't be parsed.
+	Metrics []string
+}
+
+// uses returns whether the reference's query uses metric, falling back to a
+// text search if the query couldn't be parsed.
+func (r queryReference) uses(metric string) bool {
+	if r.Metrics == nil {
+		return strings.Contains(r.Query, metric)
+	}
+	for _, m := range r.Metrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+func newQueryReference(kind string, id string, title string, q string) queryReference {
+	ref := queryReference{Kind: kind, ID: id, Title: title, Query: q}
+	if expr, err := query.Parse(q); err == nil {
+		ref.Metrics = []string{}
+		for _, m := range query.Metrics(expr) {
+			ref.Metrics = append(ref.Metrics, m.Name)
+		}
+	}
+	return ref
+}
+
+// getQueryReferences fetches every dashboard, screenboard and monitor and
+// returns the queries they contain.
+func getQueryReferences(api *datadog.API) ([]queryReference, error) {
+	refs := []queryReference{}
+
+	dashes, err := api.GetDashboards()
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range dashes {
+		log.Printf("Getting dashboard %d of %d...", i+1, len(dashes))
+		dash, err := api.GetDashboard(info.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dashboard #%s: %s", info.ID, err.Error())
+		}
+		for _, graph := range dash.Graphs {
+			for _, req := range graph.Definition.Requests {
+				refs = append(refs, newQueryReference("dashboard", info.ID, dash.Title+" / "+graph.Title, req.Q))
+			}
+		}
+	}
+
+	screenboards, err := api.GetScreenboards()
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range screenboards {
+		log.Printf("Getting screenboard %d of %d...", i+1, len(screenboards))
+		screenboard, err := api.GetScreenboard(info.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get screenboard #%d: %s", info.ID, err.Error())
+		}
+		for _, widget := range screenboard.Widgets {
+			for _, req := range widget.TileDef.Requests {
+				refs = append(refs, newQueryReference("screenboard", strconv.Itoa(info.ID), screenboard.BoardTitle+" / "+widget.TitleText, req.Q))
+			}
+		}
+	}
+
+	monitors, err := api.GetMonitors()
+	if err != nil {
+		return nil, err
+	}
+	for _, monitor := range monitors {
+		refs = append(refs, newQueryReference("monitor", strconv.Itoa(monitor.ID), monitor.Name, monitor.Query))
+	}
+
+	return refs, nil
+}
+
+func metricUsage(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("metric name required")
+	}
+	metric := c.Args()[0]
+
+	api := getAPI()
+	refs, err := getQueryReferences(api)
+	if err != nil {
+		return err
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Type", "ID", "Title", "Query"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, ref := range refs {
+		if !ref.uses(metric) {
+			continue
+		}
+		if err := w.Write([]string{ref.Kind, ref.ID, ref.Title, ref.Query}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func orphanedMetrics(c *cli.Context) error {
+	api := getAPI()
+
+	names := map[string]bool{}
+	if !c.Bool("custom-only") {
+		metrics, err := api.GetMetrics(time.Now().Add(-1 * c.Duration("duration")))
+		if err != nil {
+			return err
+		}
+		for _, m := range metrics {
+			names[m] = true
+		}
+	}
+	usage, err := api.GetTopAverageMetrics()
+	if err != nil {
+		return err
+	}
+	for _, m := range usage {
+		names[m.Name] = true
+	}
+
+	refs, err := getQueryReferences(api)
+	if err != nil {
+		return err
+	}
+
+	used := map[string]bool{}
+	unparsed := []queryReference{}
+	for _, ref := range refs {
+		if ref.Metrics == nil {
+			unparsed = append(unparsed, ref)
+			continue
+		}
+		for _, m := range ref.Metrics {
+			used[m] = true
+		}
+	}
+
+	orphaned := []string{}
+	for name := range names {
+		if used[name] {
+			continue
+		}
+		found := false
+		for _, ref := range unparsed {
+			if ref.uses(name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+
+	for _, name := range orphaned {
+		fmt.Println(name)
+	}
+	log.Printf("%d of %d metrics aren't used by any dashboard, screenboard or monitor", len(orphaned), len(names))
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/datadog/filter"
+	"github.com/urfave/cli"
+)
+
+var monitorsCommand = cli.Command{
+	Name:  "monitors",
+	Usage: "monitor commands",
+	Subcommands: []cli.Command{
+		{
+			Name:  "bulk-edit",
+			Usage: "change tags and messages of all monitors matching an expression",
+			Description: "Monitors are selected with --match, e.g. 'tag:team:old AND type:metric alert'.\n" +
+				"   Fields are id, tag, type, state, name, query and message, combined with AND, OR, NOT\n" +
+				"   and parentheses. Changes are only shown unless --apply is given.",
+			Action: bulkEditMonitors,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "match",
+					Usage: "Expression selecting the monitors to edit",
+				},
+				cli.StringSliceFlag{
+					Name:  "set-tag",
+					Usage: "Set a tag, replacing any other tags with the same key, e.g. team:new (repeatable)",
+				},
+				cli.StringSliceFlag{
+					Name:  "remove-tag",
+					Usage: "Remove a tag (repeatable)",
+				},
+				cli.StringSliceFlag{
+					Name:  "replace-message",
+					Usage: "Replace text in the message, e.g. @slack-old=@slack-new (repeatable)",
+				},
+				cli.BoolFlag{
+					Name:  "apply",
+					Usage: "Update the monitors rather than only showing the changes",
+				},
+				cli.BoolFlag{
+					Name:  "yes, y",
+					Usage: "Don't ask for confirmation before applying",
+				},
+				cli.StringFlag{
+					Name:  "log",
+					Usage: "Write a JSON log of the changes to this file",
+				},
+			},
+		},
+	},
+}
+
+type monitorChange struct {
+	ID      int              `json:"id"`
+	Name    string           `json:"name"`
+	Before  monitorEditables `json:"before"`
+	After   monitorEditables `json:"after"`
+	Applied bool             `json:"applied"`
+	Error   string           `json:"error,omitempty"`
+}
+
+type monitorEditables struct {
+	Tags    []string `json:"tags"`
+	Message string   `json:"message"`
+}
+
+func bulkEditMonitors(c *cli.Context) error {
+	if c.String("match") == "" {
+		return errors.New("--match is required")
+	}
+	expr, err := filter.Parse(c.String("match"))
+	if err != nil {
+		return errors.New("invalid match expression: " + err.Error())
+	}
+
+	replacements := [][2]string{}
+	for _, r := range c.StringSlice("replace-message") {
+		parts := strings.SplitN(r, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return errors.New("invalid --replace-message, expected old=new: " + r)
+		}
+		replacements = append(replacements, [2]string{parts[0], parts[1]})
+	}
+	setTags := c.StringSlice("set-tag")
+	removeTags := c.StringSlice("remove-tag")
+	if len(replacements) == 0 && len(setTags) == 0 && len(removeTags) == 0 {
+		return errors.New("nothing to do, use --set-tag, --remove-tag or --replace-message")
+	}
+
+	api := getAPI()
+	monitors, err := api.GetMonitors()
+	if err != nil {
+		return err
+	}
+
+	changes := []monitorChange{}
+	matched := 0
+	for _, m := range monitors {
+		if !expr.Match(m) {
+			continue
+		}
+		matched++
+
+		after := monitorEditables{
+			Tags:    editTags(m.Tags, setTags, removeTags),
+			Message: m.Message,
+		}
+		for _, r := range replacements {
+			after.Message = strings.Replace(after.Message, r[0], r[1], -1)
+		}
+		if after.Message == m.Message && strings.Join(after.Tags, "\n") == strings.Join(m.Tags, "\n") {
+			continue
+		}
+
+		changes = append(changes, monitorChange{
+			ID:     m.ID,
+			Name:   m.Name,
+			Before: monitorEditables{Tags: m.Tags, Message: m.Message},
+			After:  after,
+		})
+		fmt.Printf("Monitor %d %q\n", m.ID, m.Name)
+		printDiff(os.Stdout, "tags", strings.Join(m.Tags, "\n"), strings.Join(after.Tags, "\n"))
+		printDiff(os.Stdout, "message", m.Message, after.Message)
+	}
+	fmt.Printf("%d monitors matched, %d to change\n", matched, len(changes))
+
+	if c.Bool("apply") && len(changes) > 0 {
+		if c.Bool("yes") || confirm(fmt.Sprintf("Update %d monitors?", len(changes))) {
+			for i := range changes {
+				change := &changes[i]
+				_, err := api.UpdateMonitor(change.ID, datadog.MonitorUpdate{
+					Tags:    &change.After.Tags,
+					Message: &change.After.Message,
+				})
+				if err != nil {
+					change.Error = err.Error()
+					log.Printf("Failed to update monitor %d: %s", change.ID, err.Error())
+					continue
+				}
+				change.Applied = true
+				log.Printf("Updated monitor %d", change.ID)
+			}
+		}
+	} else if len(changes) > 0 {
+		fmt.Println("Dry run, use --apply to update monitors")
+	}
+
+	if c.String("log") != "" {
+		if err := writeJSONFile(c.String("log"), changes); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range changes {
+		if change.Error != "" {
+			return errors.New("failed to update some monitors")
+		}
+	}
+	return nil
+}
+
+// editTags returns tags with removeTags removed and setTags set. Setting a
+// key:value tag replaces any other tags with the same key, keeping its
+// position in the list.
+func editTags(tags []string, setTags []string, removeTags []string) []string {
+	result := []string{}
+	used := map[string]bool{}
+	for _, tag := range tags {
+		removed := false
+		for _, remove := range removeTags {
+			if tag == remove {
+				removed = true
+			}
+		}
+		for _, set := range setTags {
+			if tag == set || strings.Contains(set, ":") && tagKey(tag) == tagKey(set) {
+				if !used[set] {
+					result = append(result, set)
+					used[set] = true
+				}
+				removed = true
+			}
+		}
+		if !removed {
+			result = append(result, tag)
+		}
+	}
+	for _, set := range setTags {
+		if !used[set] {
+			result = append(result, set)
+			used[set] = true
+		}
+	}
+	return result
+}
+
+func tagKey(tag string) string {
+	return strings.SplitN(tag, ":", 2)[0]
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/plan"
+	"github.com/porty/ddcli/render"
+	"github.com/urfave/cli"
+)
+
+var planFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "prune",
+		Usage: "Delete dashboards or monitors that aren't in the directory, managed or not, for kinds the directory has",
+	},
+}
+
+var planCommand = cli.Command{
+	Name:      "plan",
+	Usage:     "show what apply would create, update and delete",
+	ArgsUsage: "<dir>",
+	Description: "The directory has dashboards and monitors subdirectories of JSON files, as written by\n" +
+		"   export or render --out. Files are matched to objects by their id, or by a\n" +
+		"   ddcli:managed-id:<id> tag on monitors or marker in dashboard descriptions. Files with\n" +
+		"   neither are given one from their file name. Nothing is deleted unless --prune is given, as\n" +
+		"   managed objects may come from another directory.",
+	Action: planDir,
+	Flags:  planFlags,
+}
+
+var applyCommand = cli.Command{
+	Name:        "apply",
+	Usage:       "create, update and delete dashboards and monitors to match a directory",
+	ArgsUsage:   "<dir>",
+	Description: planCommand.Description + "\n   Created and updated objects are written back to their files with their IDs.",
+	Action:      applyDir,
+	Flags: append([]cli.Flag{
+		cli.BoolFlag{
+			Name:  "yes, y",
+			Usage: "Don't ask for confirmation before applying",
+		},
+	}, planFlags...),
+}
+
+// apiLoader loads current definitions for a plan.
+type apiLoader struct {
+	api *datadog.API
+}
+
+func (l apiLoader) Dashboard(id string) (*datadog.DashboardDefinition, error) {
+	return l.api.GetDashboardDefinition(id)
+}
+
+func (l apiLoader) Monitor(id string) (*datadog.MonitorDefinition, error) {
+	monitorID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errors.New("invalid monitor id " + id)
+	}
+	return l.api.GetMonitorDefinition(monitorID)
+}
+
+// computePlan loads the desired objects from dir and works out the changes
+// needed to the org, printing them.
+func computePlan(api *datadog.API, dir string, prune bool) ([]plan.Change, error) {
+	desired, err := plan.LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// only kinds with a subdirectory are compared, so nothing else is deleted
+	kinds := plan.Kinds(dir)
+	current := []plan.Object{}
+	for _, kind := range kinds {
+		objects, err := currentObjects(api, kind)
+		if err != nil {
+			return nil, err
+		}
+		current = append(current, objects...)
+	}
+
+	changes, err := plan.Compute(desired, current, kinds, apiLoader{api}, prune)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, change := range changes {
+		fmt.Println(change.String())
+		counts[change.Action]++
+	}
+	fmt.Printf("Plan: %d to create, %d to update, %d to delete\n", counts[plan.Create], counts[plan.Update], counts[plan.Delete])
+	return changes, nil
+}
+
+// currentObjects returns the org's dashboards or monitors with only their IDs
+// and managed IDs set, along with their names.
+func currentObjects(api *datadog.API, kind string) ([]plan.Object, error) {
+	current := []plan.Object{}
+	if kind == render.KindDashboard {
+		dashes, err := api.GetDashboards()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dashes {
+			current = append(current, plan.Object{
+				Kind:      render.KindDashboard,
+				ID:        d.ID,
+				ManagedID: plan.ManagedID(nil, d.Description),
+				Dashboard: &datadog.DashboardDefinition{Title: d.Title, Description: d.Description},
+			})
+		}
+		return current, nil
+	}
+
+	monitors, err := api.GetMonitors()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range monitors {
+		current = append(current, plan.Object{
+			Kind:      render.KindMonitor,
+			ID:        strconv.Itoa(m.ID),
+			ManagedID: plan.ManagedID(m.Tags, ""),
+			Monitor:   &datadog.MonitorDefinition{Name: m.Name, Type: m.Type, Query: m.Query, Tags: m.Tags},
+		})
+	}
+	return current, nil
+}
+
+func planDir(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("directory required")
+	}
+	_, err := computePlan(getAPI(), c.Args()[0], c.Bool("prune"))
+	return err
+}
+
+func applyDir(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("directory required")
+	}
+	api := getAPI()
+	changes, err := computePlan(api, c.Args()[0], c.Bool("prune"))
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if !c.Bool("yes") && !confirm(fmt.Sprintf("Apply %d changes?", len(changes))) {
+		return errors.New("cancelled")
+	}
+
+	for _, change := range changes {
+		if err := applyChange(api, change); err != nil {
+			return fmt.Errorf("failed to apply %s: %s", change.String(), err.Error())
+		}
+		log.Print(change.String())
+	}
+	return nil
+}
+
+// applyChange makes a single change, writing created and updated objects back
+// to their files.
+func applyChange(api *datadog.API, change plan.Change) error {
+	switch change.Action {
+	case plan.Delete:
+		if change.Current.Kind == render.KindDashboard {
+			return api.DeleteDashboard(change.Current.ID)
+		}
+		id, err := strconv.Atoi(change.Current.ID)
+		if err != nil {
+			return err
+		}
+		return api.DeleteMonitor(id)
+	}
+
+	d := change.Desired
+	if d.Kind == render.KindDashboard {
+		var dash *datadog.Dashboard
+		var err error
+		if change.Action == plan.Create {
+			dash, err = api.CreateDashboard(*d.Dashboard)
+		} else {
+			dash, err = api.UpdateDashboard(change.Current.ID, *d.Dashboard)
+		}
+		if err != nil {
+			return err
+		}
+		d.ID = strconv.Itoa(dash.ID)
+	} else {
+		var monitor *datadog.Monitor
+		var err error
+		if change.Action == plan.Create {
+			monitor, err = api.CreateMonitor(*d.Monitor)
+		} else {
+			update := datadog.MonitorUpdate{
+				Name:    &d.Monitor.Name,
+				Query:   &d.Monitor.Query,
+				Message: &d.Monitor.Message,
+				Tags:    &d.Monitor.Tags,
+			}
+			if d.Monitor.Options != nil {
+				update.Options = &d.Monitor.Options
+			}
+			var id int
+			if id, err = strconv.Atoi(change.Current.ID); err != nil {
+				return err
+			}
+			monitor, err = api.UpdateMonitor(id, update)
+		}
+		if err != nil {
+			return err
+		}
+		d.ID = strconv.Itoa(monitor.ID)
+	}
+	return plan.Save(*d)
+}
@@ -0,0 +1,407 @@
+// Package plan compares a directory of desired dashboards and monitors with
+// those in a Datadog org, working out what to create, update and delete.
+//
+// Objects are matched by the id in their file, or else by a managed ID: a
+// ddcli:managed-id:<id> tag on monitors, or the same marker in a dashboard's
+// description. Files with neither are given a managed ID from their file name,
+// so that once created they are matched again.
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/render"
+)
+
+// ManagedIDPrefix starts the tag or description marker holding a managed ID.
+const ManagedIDPrefix = "ddcli:managed-id:"
+
+var managedIDRegexp = regexp.MustCompile(regexp.QuoteMeta(ManagedIDPrefix) + `([A-Za-z0-9_.\-/]+)`)
+
+// Actions.
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+// Object is a dashboard or monitor, either desired or current.
+type Object struct {
+	Kind string
+	// ID is empty for desired objects that haven't been created yet.
+	ID        string
+	ManagedID string
+	// File is the file a desired object was loaded from.
+	File      string
+	Dashboard *datadog.DashboardDefinition
+	Monitor   *datadog.MonitorDefinition
+}
+
+// Name returns the dashboard's title or the monitor's name.
+func (o Object) Name() string {
+	if o.Dashboard != nil {
+		return o.Dashboard.Title
+	}
+	if o.Monitor != nil {
+		return o.Monitor.Name
+	}
+	return ""
+}
+
+// Value returns the dashboard or monitor definition.
+func (o Object) Value() interface{} {
+	if o.Kind == render.KindDashboard {
+		return o.Dashboard
+	}
+	return o.Monitor
+}
+
+// Change is a step of a plan. For updates and deletes, Current is the object
+// in the org, and for creates and updates, Desired is the object from disk.
+type Change struct {
+	Action  string
+	Desired *Object
+	Current *Object
+	// Fields lists what differs for updates.
+	Fields []string
+}
+
+func (c Change) String() string {
+	switch c.Action {
+	case Create:
+		return fmt.Sprintf("+ create %s %q (%s)", kindName(c.Desired.Kind), c.Desired.Name(), c.Desired.File)
+	case Update:
+		return fmt.Sprintf("~ update %s %s %q: %s", kindName(c.Current.Kind), c.Current.ID, c.Desired.Name(), strings.Join(c.Fields, ", "))
+	}
+	return fmt.Sprintf("- delete %s %s %q", kindName(c.Current.Kind), c.Current.ID, c.Current.Name())
+}
+
+func kindName(kind string) string {
+	return strings.TrimSuffix(kind, "s")
+}
+
+// Loader gets the full definitions of current objects, which are only needed
+// for objects that match a desired one.
+type Loader interface {
+	Dashboard(id string) (*datadog.DashboardDefinition, error)
+	Monitor(id string) (*datadog.MonitorDefinition, error)
+}
+
+// ManagedID returns the managed ID in a monitor's tags or a dashboard's
+// description, if any.
+func ManagedID(tags []string, description string) string {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, ManagedIDPrefix) {
+			return strings.TrimPrefix(tag, ManagedIDPrefix)
+		}
+	}
+	if m := managedIDRegexp.FindStringSubmatch(description); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// Kinds returns the kinds of object dir has a subdirectory for. A plan only
+// deletes objects of these kinds, so a directory of monitors leaves dashboards
+// alone.
+func Kinds(dir string) []string {
+	kinds := []string{}
+	for _, kind := range []string{render.KindDashboard, render.KindMonitor} {
+		if info, err := os.Stat(filepath.Join(dir, kind)); err == nil && info.IsDir() {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// LoadDir loads the desired objects from the JSON files in the dashboards and
+// monitors subdirectories of dir, as written by export or render.
+func LoadDir(dir string) ([]Object, error) {
+	kinds := Kinds(dir)
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("no %s or %s directory in %s", render.KindDashboard, render.KindMonitor, dir)
+	}
+	objects := []Object{}
+	for _, kind := range kinds {
+		files, err := filepath.Glob(filepath.Join(dir, kind, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			o, err := loadFile(file, kind)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", file, err.Error())
+			}
+			objects = append(objects, o)
+		}
+	}
+
+	// managed IDs must be unique per kind to match reliably
+	seen := map[string]string{}
+	for _, o := range objects {
+		if o.ManagedID == "" {
+			continue
+		}
+		key := o.Kind + "/" + o.ManagedID
+		if other, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%s and %s have the same managed ID %q", other, o.File, o.ManagedID)
+		}
+		seen[key] = o.File
+	}
+	return objects, nil
+}
+
+func loadFile(file string, kind string) (Object, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return Object{}, err
+	}
+	ident := struct {
+		ID json.Number `json:"id"`
+	}{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&ident); err != nil {
+		return Object{}, err
+	}
+
+	o := Object{Kind: kind, ID: ident.ID.String(), File: file}
+	if o.ID == "0" {
+		o.ID = ""
+	}
+	defaultID := strings.TrimSuffix(filepath.Base(file), ".json")
+	if kind == render.KindDashboard {
+		if o.Dashboard, err = render.DecodeDashboard(b); err != nil {
+			return Object{}, err
+		}
+		o.ManagedID = ManagedID(nil, o.Dashboard.Description)
+		if o.ManagedID == "" && o.ID == "" {
+			o.ManagedID = defaultID
+			o.Dashboard.Description = strings.TrimSpace(o.Dashboard.Description + "\n\n" + ManagedIDPrefix + defaultID)
+		}
+	} else {
+		if o.Monitor, err = render.DecodeMonitor(b); err != nil {
+			return Object{}, err
+		}
+		o.ManagedID = ManagedID(o.Monitor.Tags, "")
+		if o.ManagedID == "" && o.ID == "" {
+			o.ManagedID = defaultID
+			o.Monitor.Tags = append(o.Monitor.Tags, ManagedIDPrefix+defaultID)
+		}
+	}
+	return o, nil
+}
+
+// Save writes a desired object back to its file along with its ID, e.g.
+// after it has been created.
+func Save(o Object) error {
+	var v interface{}
+	if o.Kind == render.KindDashboard {
+		v = struct {
+			ID json.Number `json:"id"`
+			*datadog.DashboardDefinition
+		}{json.Number(o.ID), o.Dashboard}
+	} else {
+		v = struct {
+			ID json.Number `json:"id"`
+			*datadog.MonitorDefinition
+		}{json.Number(o.ID), o.Monitor}
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(o.File, append(b, '\n'), 0664)
+}
+
+// Compute works out the changes needed to make current, the org's objects
+// with only their IDs and managed IDs set, match desired. Nothing is deleted
+// unless prune is set, as objects with a managed ID may come from another
+// directory. With prune, every current object of the given kinds that no
+// desired object matches is deleted. Objects of other kinds are never deleted.
+func Compute(desired []Object, current []Object, kinds []string, load Loader, prune bool) ([]Change, error) {
+	byID := map[string]*Object{}
+	byManagedID := map[string]*Object{}
+	for i := range current {
+		c := &current[i]
+		byID[c.Kind+"/"+c.ID] = c
+		if c.ManagedID != "" {
+			byManagedID[c.Kind+"/"+c.ManagedID] = c
+		}
+	}
+
+	changes := []Change{}
+	matched := map[*Object]bool{}
+	for i := range desired {
+		d := &desired[i]
+		var c *Object
+		if d.ID != "" {
+			c = byID[d.Kind+"/"+d.ID]
+		}
+		if c == nil && d.ManagedID != "" {
+			c = byManagedID[d.Kind+"/"+d.ManagedID]
+		}
+		if c == nil {
+			if d.ID != "" {
+				return nil, fmt.Errorf("%s: %s %s doesn't exist, remove its id to create it", d.File, kindName(d.Kind), d.ID)
+			}
+			changes = append(changes, Change{Action: Create, Desired: d})
+			continue
+		}
+		if matched[c] {
+			return nil, fmt.Errorf("%s: %s %s is already matched by another file", d.File, kindName(c.Kind), c.ID)
+		}
+		matched[c] = true
+
+		var fields []string
+		if d.Kind == render.KindDashboard {
+			def, err := load.Dashboard(c.ID)
+			if err != nil {
+				return nil, err
+			}
+			c.Dashboard = def
+			fields = diffDashboard(*d.Dashboard, *def)
+		} else {
+			def, err := load.Monitor(c.ID)
+			if err != nil {
+				return nil, err
+			}
+			c.Monitor = def
+			fields = diffMonitor(*d.Monitor, *def)
+		}
+		if len(fields) > 0 {
+			changes = append(changes, Change{Action: Update, Desired: d, Current: c, Fields: fields})
+		}
+	}
+
+	if !prune {
+		return changes, nil
+	}
+	deletable := map[string]bool{}
+	for _, kind := range kinds {
+		deletable[kind] = true
+	}
+	for i := range current {
+		c := &current[i]
+		if matched[c] || !deletable[c.Kind] {
+			continue
+		}
+		changes = append(changes, Change{Action: Delete, Current: c})
+	}
+	return changes, nil
+}
+
+func diffMonitor(desired datadog.MonitorDefinition, current datadog.MonitorDefinition) []string {
+	fields := []string{}
+	if desired.Name != current.Name {
+		fields = append(fields, "name")
+	}
+	if desired.Type != current.Type {
+		fields = append(fields, "type")
+	}
+	if desired.Query != current.Query {
+		fields = append(fields, "query")
+	}
+	if desired.Message != current.Message {
+		fields = append(fields, "message")
+	}
+	if !sameStrings(desired.Tags, current.Tags) {
+		fields = append(fields, "tags")
+	}
+	if !subset(toJSONValue(desired.Options), toJSONValue(current.Options)) {
+		fields = append(fields, "options")
+	}
+	return fields
+}
+
+func diffDashboard(desired datadog.DashboardDefinition, current datadog.DashboardDefinition) []string {
+	fields := []string{}
+	if desired.Title != current.Title {
+		fields = append(fields, "title")
+	}
+	if desired.Description != current.Description {
+		fields = append(fields, "description")
+	}
+	if desired.ReadOnly != current.ReadOnly {
+		fields = append(fields, "read_only")
+	}
+	if len(desired.Graphs) != len(current.Graphs) || !subset(toJSONValue(desired.Graphs), toJSONValue(current.Graphs)) {
+		fields = append(fields, "graphs")
+	}
+	if !subset(toJSONValue(desired.TemplateVariables), toJSONValue(current.TemplateVariables)) {
+		fields = append(fields, "template_variables")
+	}
+	return fields
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
+
+// toJSONValue converts v to the generic form decoded JSON takes, so numbers
+// compare as float64 whatever type they were given as.
+func toJSONValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// subset returns whether everything set in desired has the same value in
+// current. Datadog fills in defaults for many settings, so current may have
+// more keys than were asked for. Lists must be the same length.
+func subset(desired interface{}, current interface{}) bool {
+	switch d := desired.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			return len(d) == 0
+		}
+		for k, v := range d {
+			if !subset(v, c[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok {
+			return len(d) == 0
+		}
+		if len(d) != len(c) {
+			return false
+		}
+		for i := range d {
+			if !subset(d[i], c[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(desired, current)
+}
@@ -0,0 +1,217 @@
+package plan
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/render"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLoader struct {
+	dashboards map[string]*datadog.DashboardDefinition
+	monitors   map[string]*datadog.MonitorDefinition
+}
+
+func (l fakeLoader) Dashboard(id string) (*datadog.DashboardDefinition, error) {
+	return l.dashboards[id], nil
+}
+
+func (l fakeLoader) Monitor(id string) (*datadog.MonitorDefinition, error) {
+	return l.monitors[id], nil
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "plan")
+	require.NoError(t, err)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0777))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0666))
+	}
+	return dir
+}
+
+func TestManagedID(t *testing.T) {
+	require.Equal(t, "checkout-cpu", ManagedID([]string{"env:prod", "ddcli:managed-id:checkout-cpu"}, ""))
+	require.Equal(t, "hosts", ManagedID(nil, "Host overview\n\nddcli:managed-id:hosts"))
+	require.Equal(t, "", ManagedID([]string{"env:prod"}, "no marker"))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"dashboards/hosts.json": `{"title": "Hosts", "description": "All hosts", "graphs": []}`,
+		"monitors/123.json":     `{"id": 123, "name": "CPU", "type": "metric alert", "query": "avg(last_5m):avg:system.cpu.user{*} > 90", "tags": ["team:ops"]}`,
+		"monitors/disk.json":    `{"name": "Disk", "type": "metric alert", "query": "avg(last_5m):avg:system.disk.in_use{*} > 0.9", "tags": ["ddcli:managed-id:disk-usage"]}`,
+		"monitors/notes.txt":    `ignored`,
+	})
+	defer os.RemoveAll(dir)
+
+	objects, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+
+	// files without an id or managed ID get one from their file name
+	require.Equal(t, render.KindDashboard, objects[0].Kind)
+	require.Equal(t, "", objects[0].ID)
+	require.Equal(t, "hosts", objects[0].ManagedID)
+	require.Equal(t, "All hosts\n\nddcli:managed-id:hosts", objects[0].Dashboard.Description)
+
+	require.Equal(t, "123", objects[1].ID)
+	require.Equal(t, "", objects[1].ManagedID)
+	require.Equal(t, []string{"team:ops"}, objects[1].Monitor.Tags)
+
+	require.Equal(t, "", objects[2].ID)
+	require.Equal(t, "disk-usage", objects[2].ManagedID)
+}
+
+func TestLoadDirDuplicateManagedID(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"monitors/a.json": `{"name": "A", "type": "service check", "query": "x", "tags": ["ddcli:managed-id:same"]}`,
+		"monitors/b.json": `{"name": "B", "type": "service check", "query": "x", "tags": ["ddcli:managed-id:same"]}`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := LoadDir(dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `have the same managed ID "same"`)
+}
+
+func TestCompute(t *testing.T) {
+	cpu := &datadog.MonitorDefinition{Name: "CPU", Type: "metric alert", Query: "q1", Tags: []string{"team:ops"}, Options: map[string]interface{}{"thresholds": map[string]interface{}{"critical": 90}}}
+	disk := &datadog.MonitorDefinition{Name: "Disk", Type: "metric alert", Query: "q2", Tags: []string{"ddcli:managed-id:disk"}}
+	hosts := &datadog.DashboardDefinition{Title: "Hosts", Description: "ddcli:managed-id:hosts", Graphs: []map[string]interface{}{{"title": "CPU"}}}
+	desired := []Object{
+		{Kind: render.KindMonitor, ID: "1", File: "monitors/1.json", Monitor: cpu},
+		{Kind: render.KindMonitor, ManagedID: "disk", File: "monitors/disk.json", Monitor: disk},
+		{Kind: render.KindMonitor, ManagedID: "new", File: "monitors/new.json", Monitor: &datadog.MonitorDefinition{Name: "New"}},
+		{Kind: render.KindDashboard, ManagedID: "hosts", File: "dashboards/hosts.json", Dashboard: hosts},
+	}
+	current := []Object{
+		{Kind: render.KindMonitor, ID: "1", Monitor: &datadog.MonitorDefinition{Name: "CPU"}},
+		{Kind: render.KindMonitor, ID: "2", ManagedID: "disk", Monitor: &datadog.MonitorDefinition{Name: "Disk"}},
+		{Kind: render.KindMonitor, ID: "3", ManagedID: "removed", Monitor: &datadog.MonitorDefinition{Name: "Removed"}},
+		{Kind: render.KindMonitor, ID: "4", Monitor: &datadog.MonitorDefinition{Name: "Unmanaged"}},
+		{Kind: render.KindDashboard, ID: "10", ManagedID: "hosts", Dashboard: &datadog.DashboardDefinition{Title: "Hosts"}},
+	}
+	load := fakeLoader{
+		monitors: map[string]*datadog.MonitorDefinition{
+			// the same apart from defaults filled in by Datadog
+			"1": {Name: "CPU", Type: "metric alert", Query: "q1", Tags: []string{"team:ops"}, Options: map[string]interface{}{
+				"thresholds":     map[string]interface{}{"critical": 90.0, "warning": nil},
+				"notify_no_data": false,
+			}},
+			"2": {Name: "Disk", Type: "metric alert", Query: "old", Message: "hi", Tags: []string{"ddcli:managed-id:disk"}},
+		},
+		dashboards: map[string]*datadog.DashboardDefinition{
+			"10": {Title: "Hosts", Description: "ddcli:managed-id:hosts", Graphs: []map[string]interface{}{{"title": "CPU", "definition": map[string]interface{}{}}}},
+		},
+	}
+
+	kinds := []string{render.KindDashboard, render.KindMonitor}
+	changes, err := Compute(desired, current, kinds, load, false)
+	require.NoError(t, err)
+	lines := []string{}
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	require.Equal(t, []string{
+		`~ update monitor 2 "Disk": query, message`,
+		`+ create monitor "New" (monitors/new.json)`,
+	}, lines)
+
+	// pruning deletes everything not in the directory, managed or not
+	changes, err = Compute(desired, current, kinds, load, true)
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+	require.Equal(t, `- delete monitor 3 "Removed"`, changes[2].String())
+	require.Equal(t, `- delete monitor 4 "Unmanaged"`, changes[3].String())
+}
+
+func TestComputeOtherDirectory(t *testing.T) {
+	// team A's directory, while team B manages another monitor and dashboard
+	// from its own
+	desired := []Object{
+		{Kind: render.KindMonitor, ManagedID: "a-cpu", File: "monitors/a-cpu.json", Monitor: &datadog.MonitorDefinition{Name: "A CPU"}},
+		{Kind: render.KindDashboard, ManagedID: "a-hosts", File: "dashboards/a-hosts.json", Dashboard: &datadog.DashboardDefinition{Title: "A hosts"}},
+	}
+	current := []Object{
+		{Kind: render.KindMonitor, ID: "1", ManagedID: "a-cpu", Monitor: &datadog.MonitorDefinition{Name: "A CPU"}},
+		{Kind: render.KindMonitor, ID: "2", ManagedID: "b-disk", Monitor: &datadog.MonitorDefinition{Name: "B disk"}},
+		{Kind: render.KindDashboard, ID: "10", ManagedID: "a-hosts", Dashboard: &datadog.DashboardDefinition{Title: "A hosts"}},
+		{Kind: render.KindDashboard, ID: "11", ManagedID: "b-overview", Dashboard: &datadog.DashboardDefinition{Title: "B overview"}},
+	}
+	load := fakeLoader{
+		monitors:   map[string]*datadog.MonitorDefinition{"1": {Name: "A CPU"}},
+		dashboards: map[string]*datadog.DashboardDefinition{"10": {Title: "A hosts"}},
+	}
+
+	changes, err := Compute(desired, current, []string{render.KindDashboard, render.KindMonitor}, load, false)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func TestComputeOnlyDeletesKinds(t *testing.T) {
+	// a directory with only monitors
+	desired := []Object{
+		{Kind: render.KindMonitor, ManagedID: "m", File: "monitors/m.json", Monitor: &datadog.MonitorDefinition{Name: "M"}},
+	}
+	current := []Object{
+		{Kind: render.KindMonitor, ID: "1", ManagedID: "m", Monitor: &datadog.MonitorDefinition{Name: "M"}},
+		{Kind: render.KindMonitor, ID: "2", ManagedID: "gone", Monitor: &datadog.MonitorDefinition{Name: "Gone"}},
+		{Kind: render.KindMonitor, ID: "3", Monitor: &datadog.MonitorDefinition{Name: "Unmanaged"}},
+		{Kind: render.KindDashboard, ID: "10", ManagedID: "other-team", Dashboard: &datadog.DashboardDefinition{Title: "Other team"}},
+		{Kind: render.KindDashboard, ID: "11", Dashboard: &datadog.DashboardDefinition{Title: "Unmanaged"}},
+	}
+	load := fakeLoader{monitors: map[string]*datadog.MonitorDefinition{"1": {Name: "M"}}}
+
+	for _, prune := range []bool{false, true} {
+		changes, err := Compute(desired, current, []string{render.KindMonitor}, load, prune)
+		require.NoError(t, err)
+		for _, c := range changes {
+			require.Equal(t, Delete, c.Action)
+			require.Equal(t, render.KindMonitor, c.Current.Kind, c.String())
+		}
+		if prune {
+			require.Len(t, changes, 2)
+		} else {
+			require.Empty(t, changes)
+		}
+	}
+}
+
+func TestKinds(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"monitors/m.json": `{}`,
+		"dashboards":      `not a directory`,
+	})
+	defer os.RemoveAll(dir)
+	require.Equal(t, []string{render.KindMonitor}, Kinds(dir))
+}
+
+func TestComputeMissingID(t *testing.T) {
+	desired := []Object{
+		{Kind: render.KindMonitor, ID: "99", File: "monitors/99.json", Monitor: &datadog.MonitorDefinition{Name: "Gone"}},
+	}
+	_, err := Compute(desired, nil, []string{render.KindMonitor}, fakeLoader{}, false)
+	require.EqualError(t, err, "monitors/99.json: monitor 99 doesn't exist, remove its id to create it")
+}
+
+func TestSave(t *testing.T) {
+	dir := writeFiles(t, map[string]string{})
+	defer os.RemoveAll(dir)
+
+	o := Object{
+		Kind:    render.KindMonitor,
+		ID:      "3001",
+		File:    filepath.Join(dir, "cpu.json"),
+		Monitor: &datadog.MonitorDefinition{Name: "CPU", Type: "metric alert", Query: "q", Tags: []string{"ddcli:managed-id:cpu"}},
+	}
+	require.NoError(t, Save(o))
+	b, err := ioutil.ReadFile(o.File)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id": 3001, "name": "CPU", "type": "metric alert", "query": "q", "message": "", "tags": ["ddcli:managed-id:cpu"]}`, string(b))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/plan"
+	"github.com/porty/ddcli/render"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyChange(t *testing.T) {
+	requests := []string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/monitor":
+			fmt.Fprint(w, `{"id": 3001, "name": "CPU"}`)
+		case "PUT /api/v1/monitor/3002":
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"name": "Memory", "query": "q2", "message": "", "tags": ["ddcli:managed-id:memory"]}`, string(b))
+			fmt.Fprint(w, `{"id": 3002, "name": "Memory"}`)
+		case "POST /api/v1/dash":
+			fmt.Fprint(w, `{"dash": {"id": 4001, "title": "Checkout"}}`)
+		case "DELETE /api/v1/monitor/3003", "DELETE /api/v1/dash/4002":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := datadog.NewWithBaseURL("api-key", "app-key", server.URL)
+
+	dir, err := ioutil.TempDir("", "ddcli-apply")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cpu := &plan.Object{
+		Kind:    render.KindMonitor,
+		File:    filepath.Join(dir, "cpu.json"),
+		Monitor: &datadog.MonitorDefinition{Name: "CPU", Type: "metric alert", Query: "q1", Tags: []string{"ddcli:managed-id:cpu"}},
+	}
+	memory := &plan.Object{
+		Kind:    render.KindMonitor,
+		File:    filepath.Join(dir, "memory.json"),
+		Monitor: &datadog.MonitorDefinition{Name: "Memory", Type: "metric alert", Query: "q2", Tags: []string{"ddcli:managed-id:memory"}},
+	}
+	checkout := &plan.Object{
+		Kind:      render.KindDashboard,
+		File:      filepath.Join(dir, "checkout.json"),
+		Dashboard: &datadog.DashboardDefinition{Title: "Checkout", Graphs: []map[string]interface{}{}},
+	}
+	changes := []plan.Change{
+		{Action: plan.Create, Desired: cpu},
+		{Action: plan.Update, Desired: memory, Current: &plan.Object{Kind: render.KindMonitor, ID: "3002"}},
+		{Action: plan.Create, Desired: checkout},
+		{Action: plan.Delete, Current: &plan.Object{Kind: render.KindMonitor, ID: "3003"}},
+		{Action: plan.Delete, Current: &plan.Object{Kind: render.KindDashboard, ID: "4002"}},
+	}
+	for _, change := range changes {
+		require.NoError(t, applyChange(api, change))
+	}
+
+	require.Equal(t, []string{
+		"POST /api/v1/monitor",
+		"PUT /api/v1/monitor/3002",
+		"POST /api/v1/dash",
+		"DELETE /api/v1/monitor/3003",
+		"DELETE /api/v1/dash/4002",
+	}, requests)
+
+	// created and updated objects are written back with their IDs
+	for file, expected := range map[string]string{
+		"cpu.json":      `{"id": 3001, "name": "CPU", "type": "metric alert", "query": "q1", "message": "", "tags": ["ddcli:managed-id:cpu"]}`,
+		"memory.json":   `{"id": 3002, "name": "Memory", "type": "metric alert", "query": "q2", "message": "", "tags": ["ddcli:managed-id:memory"]}`,
+		"checkout.json": `{"id": 4001, "title": "Checkout", "description": "", "graphs": []}`,
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, file))
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(b), file)
+	}
+}
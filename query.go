@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/porty/ddcli/datadog/query"
+	"github.com/urfave/cli"
+)
+
+var queryCommand = cli.Command{
+	Name:  "query",
+	Usage: "metric and monitor query commands",
+	Subcommands: []cli.Command{
+		{
+			Name:      "parse",
+			Usage:     "parse a metric or monitor query and print its syntax tree as JSON",
+			ArgsUsage: "<query>",
+			Action:    parseQuery,
+		},
+		{
+			Name:      "validate",
+			Usage:     "check metric or monitor queries, one per line on stdin if none are given",
+			ArgsUsage: "[query...]",
+			Action:    validateQueries,
+		},
+	},
+}
+
+func parseQuery(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("query required")
+	}
+	expr, err := query.Parse(c.Args()[0])
+	if err != nil {
+		return err
+	}
+	return printJSON(expr)
+}
+
+func validateQueries(c *cli.Context) error {
+	queries := []string(c.Args())
+	if len(queries) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				queries = append(queries, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return errors.New("failed to read queries: " + err.Error())
+		}
+	}
+
+	invalid := 0
+	for _, q := range queries {
+		expr, err := query.Parse(q)
+		if err != nil {
+			invalid++
+			fmt.Printf("INVALID %s\n  %s\n", q, err.Error())
+			continue
+		}
+		if errs := query.Validate(expr); len(errs) > 0 {
+			invalid++
+			fmt.Printf("INVALID %s\n", q)
+			for _, err := range errs {
+				fmt.Println("  " + err.Error())
+			}
+			continue
+		}
+		fmt.Printf("OK      %s\n", q)
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d queries are invalid", invalid, len(queries))
+	}
+	return nil
+}
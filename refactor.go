@@ -0,0 +1,228 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/datadog/query"
+	"github.com/urfave/cli"
+)
+
+var refactorFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "apply",
+		Usage: "Update the dashboards and monitors rather than only showing the changes",
+	},
+	cli.BoolFlag{
+		Name:  "yes, y",
+		Usage: "Don't ask for confirmation before applying",
+	},
+}
+
+var refactorCommand = cli.Command{
+	Name:  "refactor",
+	Usage: "rewrite queries across all timeboards, screenboards and monitors",
+	Subcommands: []cli.Command{
+		{
+			Name:      "rename-metric",
+			Usage:     "rename a metric in every query, e.g. app.req.count app.requests.total",
+			ArgsUsage: "<old> <new>",
+			Description: "Graph and widget requests and metric monitor queries are rewritten. Changes are only\n" +
+				"   shown unless --apply is given. Queries mentioning the metric that can't be parsed or\n" +
+				"   rewritten are listed and left alone, and make the command exit non-zero.",
+			Action: renameMetric,
+			Flags:  refactorFlags,
+		},
+		{
+			Name:      "rename-tag",
+			Usage:     "replace a tag in every query's scope, e.g. service:old service:new",
+			ArgsUsage: "<key:old> <key:new>",
+			Description: "Graph and widget requests and metric monitor queries are rewritten, including where the\n" +
+				"   tag is excluded with !. Changes are only shown unless --apply is given. Queries mentioning\n" +
+				"   the tag that can't be parsed or rewritten, such as in {service:old AND env:prod}, are listed\n" +
+				"   and left alone, and make the command exit non-zero.",
+			Action: renameTag,
+			Flags:  refactorFlags,
+		},
+	},
+}
+
+// refactorChange is a dashboard or monitor with rewritten queries.
+type refactorChange struct {
+	Name  string
+	Apply func() error
+}
+
+func renameMetric(c *cli.Context) error {
+	if c.NArg() != 2 || c.Args()[0] == "" || c.Args()[1] == "" {
+		return errors.New("old and new metric names required")
+	}
+	from, to := c.Args()[0], c.Args()[1]
+	return refactorQueries(c, from, func(expr query.Expr) bool {
+		return query.RenameMetric(expr, from, to)
+	})
+}
+
+func renameTag(c *cli.Context) error {
+	if c.NArg() != 2 || !strings.Contains(c.Args()[0], ":") || !strings.Contains(c.Args()[1], ":") {
+		return errors.New("old and new tags required, e.g. service:old service:new")
+	}
+	from, to := c.Args()[0], c.Args()[1]
+	return refactorQueries(c, from, func(expr query.Expr) bool {
+		return query.RenameTag(expr, from, to)
+	})
+}
+
+// refactorQueries rewrites every query in the org with rewrite, printing the
+// changes and applying them if asked. Queries mentioning old that can't be
+// parsed or rewritten are reported and left alone, and make the command fail
+// once the rest are done so they aren't missed.
+func refactorQueries(c *cli.Context, old string, rewrite func(query.Expr) bool) error {
+	changes, skipped, err := findRefactorChanges(getAPI(), os.Stdout, old, rewrite)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d dashboards and monitors to change, %d queries mentioning %s skipped\n", len(changes), skipped, old)
+	var skippedErr error
+	if skipped > 0 {
+		skippedErr = fmt.Errorf("%d queries mentioning %s couldn't be rewritten and need changing by hand", skipped, old)
+	}
+
+	if len(changes) == 0 {
+		return skippedErr
+	}
+	if !c.Bool("apply") {
+		fmt.Println("Dry run, use --apply to update them")
+		return skippedErr
+	}
+	if !c.Bool("yes") && !confirm(fmt.Sprintf("Update %d dashboards and monitors?", len(changes))) {
+		return errors.New("cancelled")
+	}
+	failed := false
+	for _, change := range changes {
+		if err := change.Apply(); err != nil {
+			log.Printf("Failed to update %s: %s", change.Name, err.Error())
+			failed = true
+			continue
+		}
+		log.Printf("Updated %s", change.Name)
+	}
+	if failed {
+		return errors.New("failed to update some dashboards and monitors")
+	}
+	return skippedErr
+}
+
+// findRefactorChanges rewrites the queries of every dashboard, screenboard and
+// metric monitor with rewrite, writing a diff of each change to w. It also
+// returns how many queries mentioning old were skipped as they couldn't be
+// parsed, or still mention it after being rewritten.
+func findRefactorChanges(api *datadog.API, w io.Writer, old string, rewrite func(query.Expr) bool) ([]refactorChange, int, error) {
+	skipped := 0
+	rewriteQuery := func(where string, q string) (string, bool) {
+		after, changed, err := query.Rewrite(q, rewrite)
+		if err != nil {
+			if strings.Contains(q, old) {
+				log.Printf("Skipping query in %s, failed to parse %q: %s", where, q, err.Error())
+				skipped++
+			}
+			return q, false
+		}
+		// such as a tag in a boolean scope like {service:old AND env:prod},
+		// which is parsed as a single tag
+		if containsWhole(after, old, isTagChar) {
+			log.Printf("Skipping %q in %s, couldn't rewrite it in %q", old, where, after)
+			skipped++
+		}
+		return after, changed
+	}
+	// rewriteRequests rewrites requests in place, printing a diff under the
+	// board's heading if any changed
+	rewriteRequests := func(heading string, requests []datadog.GraphRequest) bool {
+		changed := false
+		for _, r := range requests {
+			q, _ := r.Request["q"].(string)
+			after, ok := rewriteQuery(heading, q)
+			if !ok {
+				continue
+			}
+			if !changed {
+				fmt.Fprintln(w, heading)
+				changed = true
+			}
+			printDiff(w, strconv.Quote(r.Title), q, after)
+			r.Request["q"] = after
+		}
+		return changed
+	}
+
+	changes := []refactorChange{}
+
+	dashes, err := api.GetDashboards()
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, d := range dashes {
+		def, err := api.GetDashboardDefinition(d.ID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get dashboard %s: %s", d.ID, err.Error())
+		}
+		name := fmt.Sprintf("dashboard %s %q", d.ID, d.Title)
+		if rewriteRequests(fmt.Sprintf("Dashboard %s %q", d.ID, d.Title), def.Requests()) {
+			id := d.ID
+			changes = append(changes, refactorChange{name, func() error {
+				_, err := api.UpdateDashboard(id, *def)
+				return err
+			}})
+		}
+	}
+
+	screenboards, err := api.GetScreenboards()
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, s := range screenboards {
+		def, err := api.GetScreenboardDefinition(s.ID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get screenboard %d: %s", s.ID, err.Error())
+		}
+		name := fmt.Sprintf("screenboard %d %q", s.ID, s.Title)
+		if rewriteRequests(fmt.Sprintf("Screenboard %d %q", s.ID, s.Title), def.Requests()) {
+			id := s.ID
+			changes = append(changes, refactorChange{name, func() error {
+				_, err := api.UpdateScreenboard(id, *def)
+				return err
+			}})
+		}
+	}
+
+	monitors, err := api.GetMonitors()
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, m := range monitors {
+		// other monitor types have their own query syntax
+		if m.Type != "metric alert" && m.Type != "query alert" {
+			continue
+		}
+		name := fmt.Sprintf("monitor %d %q", m.ID, m.Name)
+		after, ok := rewriteQuery(name, m.Query)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "Monitor %d %q\n", m.ID, m.Name)
+		printDiff(w, "query", m.Query, after)
+		id := m.ID
+		changes = append(changes, refactorChange{name, func() error {
+			_, err := api.UpdateMonitor(id, datadog.MonitorUpdate{Query: &after})
+			return err
+		}})
+	}
+	return changes, skipped, nil
+}
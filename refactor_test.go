@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/datadog/query"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRefactorChanges(t *testing.T) {
+	updates := map[string]string{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/dash":
+			fmt.Fprint(w, `{"dashes": [{"id": "1", "title": "Checkout"}, {"id": "2", "title": "Unrelated"}]}`)
+		case "GET /api/v1/dash/1":
+			fmt.Fprint(w, `{"dash": {"title": "Checkout", "graphs": [
+				{"title": "Requests", "definition": {"requests": [
+					{"q": "sum:app.req.count{env:prod} by {host}.rollup(sum, 60)"},
+					{"q": "sum:app.req.count{env:prod"},
+					{"q": "sum:app.errors{*}"}
+				]}}
+			]}}`)
+		case "GET /api/v1/dash/2":
+			fmt.Fprint(w, `{"dash": {"title": "Unrelated", "graphs": [
+				{"title": "Errors", "definition": {"requests": [{"q": "sum:app.errors{*"}]}}
+			]}}`)
+		case "GET /api/v1/screen":
+			fmt.Fprint(w, `{"screenboards": [{"id": 3, "title": "Ops"}]}`)
+		case "GET /api/v1/screen/3":
+			fmt.Fprint(w, `{"board_title": "Ops", "widgets": [
+				{"type": "timeseries", "title_text": "Traffic", "tile_def": {"requests": [{"q": "sum:app.req.count{*}, sum:app.errors{*}"}]}}
+			]}`)
+		case "GET /api/v1/monitor":
+			fmt.Fprint(w, `[
+				{"id": 4, "name": "Requests", "type": "query alert", "query": "avg(last_4h):anomalies(sum:app.req.count{*}, 'basic', 2, direction='above') >= 1e-5"},
+				{"id": 5, "name": "Broken", "type": "query alert", "query": "avg(last_5m):sum:app.req.count{*} >"},
+				{"id": 6, "name": "Logs", "type": "log alert", "query": "logs(\"app.req.count\").index(\"*\").rollup(\"count\").last(\"5m\") > 1"}
+			]`)
+		case "PUT /api/v1/dash/1", "PUT /api/v1/screen/3", "PUT /api/v1/monitor/4":
+			b, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			updates[r.URL.Path] = string(b)
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := datadog.NewWithBaseURL("api-key", "app-key", server.URL)
+
+	out := &bytes.Buffer{}
+	changes, skipped, err := findRefactorChanges(api, out, "app.req.count", func(expr query.Expr) bool {
+		return query.RenameMetric(expr, "app.req.count", "app.requests.total")
+	})
+	require.NoError(t, err)
+	// the unparseable dashboard request and monitor query mention the metric,
+	// the unparseable request on the unrelated dashboard doesn't
+	require.Equal(t, 2, skipped)
+
+	names := []string{}
+	for _, c := range changes {
+		names = append(names, c.Name)
+	}
+	require.Equal(t, []string{`dashboard 1 "Checkout"`, `screenboard 3 "Ops"`, `monitor 4 "Requests"`}, names)
+	require.Contains(t, out.String(), "+ sum:app.requests.total{env:prod} by {host}.rollup(sum, 60)")
+	require.Contains(t, out.String(), "+ sum:app.requests.total{*}, sum:app.errors{*}")
+
+	for _, c := range changes {
+		require.NoError(t, c.Apply())
+	}
+	require.JSONEq(t, `{"title": "Checkout", "description": "", "graphs": [
+		{"title": "Requests", "definition": {"requests": [
+			{"q": "sum:app.requests.total{env:prod} by {host}.rollup(sum, 60)"},
+			{"q": "sum:app.req.count{env:prod"},
+			{"q": "sum:app.errors{*}"}
+		]}}
+	]}`, updates["/api/v1/dash/1"])
+	require.Contains(t, updates["/api/v1/screen/3"], `"q":"sum:app.requests.total{*}, sum:app.errors{*}"`)
+	require.JSONEq(t, `{"query": "avg(last_4h):anomalies(sum:app.requests.total{*}, 'basic', 2, direction='above') >= 1e-5"}`, updates["/api/v1/monitor/4"])
+}
+
+func TestFindRefactorChangesBooleanScope(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/dash":
+			fmt.Fprint(w, `{"dashes": [{"id": "1", "title": "Checkout"}]}`)
+		case "GET /api/v1/dash/1":
+			fmt.Fprint(w, `{"dash": {"title": "Checkout", "graphs": [
+				{"title": "Requests", "definition": {"requests": [
+					{"q": "sum:app.requests{service:old}, sum:app.errors{service:old OR env:staging}"},
+					{"q": "sum:app.requests{service:old-canary}"}
+				]}}
+			]}}`)
+		case "GET /api/v1/screen":
+			fmt.Fprint(w, `{"screenboards": []}`)
+		case "GET /api/v1/monitor":
+			fmt.Fprint(w, `[
+				{"id": 4, "name": "Errors", "type": "metric alert", "query": "avg(last_5m):sum:app.errors{service:old AND env:prod} > 10"}
+			]`)
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	api := datadog.NewWithBaseURL("api-key", "app-key", server.URL)
+
+	changes, skipped, err := findRefactorChanges(api, ioutil.Discard, "service:old", func(expr query.Expr) bool {
+		return query.RenameTag(expr, "service:old", "service:new")
+	})
+	require.NoError(t, err)
+	// the dashboard's first series is renamed, but its second and the monitor
+	// have boolean scopes that still mention the tag, while service:old-canary
+	// is a different tag
+	require.Equal(t, 2, skipped)
+	require.Len(t, changes, 1)
+	require.Equal(t, `dashboard 1 "Checkout"`, changes[0].Name)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path"
+
+	"github.com/porty/ddcli/render"
+	"github.com/urfave/cli"
+)
+
+var renderCommand = cli.Command{
+	Name:      "render",
+	Usage:     "render dashboard and monitor templates, then write them to disk or create them",
+	ArgsUsage: "<template dir>",
+	Description: "Files in the dashboards and monitors subdirectories of the template directory are Go\n" +
+		"   text/template files that render to a JSON object or a list of objects. Besides the standard\n" +
+		"   functions, split, join, lower, upper, replace, trim and json are available, e.g.\n" +
+		"   {{range split .endpoints \"|\"}}...{{end}}. Rendered objects are printed unless --out or\n" +
+		"   --create is given.",
+	Action: renderTemplates,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "vars",
+			Usage: "Comma separated variables, e.g. service=checkout,env=prod",
+		},
+		cli.StringFlag{
+			Name:  "vars-file",
+			Usage: "JSON file of variables, which may include lists, overridden by --vars",
+		},
+		cli.StringFlag{
+			Name:  "out, o",
+			Usage: "Write the rendered objects to dashboards and monitors subdirectories of this directory",
+		},
+		cli.BoolFlag{
+			Name:  "create",
+			Usage: "Create the rendered dashboards and monitors in Datadog",
+		},
+	},
+}
+
+func renderTemplates(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return errors.New("template directory required")
+	}
+
+	vars := map[string]interface{}{}
+	if c.String("vars-file") != "" {
+		b, err := ioutil.ReadFile(c.String("vars-file"))
+		if err != nil {
+			return errors.New("failed to read --vars-file: " + err.Error())
+		}
+		if err := json.Unmarshal(b, &vars); err != nil {
+			return errors.New("invalid --vars-file: " + err.Error())
+		}
+	}
+	flagVars, err := render.ParseVars(c.String("vars"))
+	if err != nil {
+		return errors.New("invalid --vars: " + err.Error())
+	}
+	for k, v := range flagVars {
+		vars[k] = v
+	}
+
+	objects, err := render.Dir(c.Args()[0], vars)
+	if err != nil {
+		return err
+	}
+
+	if c.String("out") == "" && !c.Bool("create") {
+		for _, o := range objects {
+			if err := printJSON(o.Value()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if dir := c.String("out"); dir != "" {
+		createDirectories(path.Join(dir, render.KindDashboard), path.Join(dir, render.KindMonitor))
+		for _, o := range objects {
+			if err := writeJSONFile(path.Join(dir, o.Kind, o.Name+".json"), o.Value()); err != nil {
+				return err
+			}
+		}
+		log.Printf("Wrote %d objects to %s", len(objects), dir)
+	}
+
+	if c.Bool("create") {
+		api := getAPI()
+		for _, o := range objects {
+			switch o.Kind {
+			case render.KindDashboard:
+				dash, err := api.CreateDashboard(*o.Dashboard)
+				if err != nil {
+					return fmt.Errorf("failed to create dashboard %q: %s", o.Dashboard.Title, err.Error())
+				}
+				log.Printf("Created dashboard %d %q", dash.ID, dash.Title)
+			case render.KindMonitor:
+				monitor, err := api.CreateMonitor(*o.Monitor)
+				if err != nil {
+					return fmt.Errorf("failed to create monitor %q: %s", o.Monitor.Name, err.Error())
+				}
+				log.Printf("Created monitor %d %q", monitor.ID, monitor.Name)
+			}
+		}
+	}
+	return nil
+}
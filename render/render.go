@@ -0,0 +1,227 @@
+// Package render renders directories of Go text/template files into
+// dashboard and monitor definitions, checking them before anything is sent to
+// Datadog.
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/porty/ddcli/datadog/query"
+)
+
+// Object kinds, which are also the names of the template directory's
+// subdirectories.
+const (
+	KindDashboard = "dashboards"
+	KindMonitor   = "monitors"
+)
+
+// Object is a dashboard or monitor rendered from a template.
+type Object struct {
+	Kind string
+	// Name is the template's file name without extensions, with a -N suffix if
+	// it rendered a list of objects.
+	Name      string
+	Dashboard *datadog.DashboardDefinition
+	Monitor   *datadog.MonitorDefinition
+}
+
+// Value returns the dashboard or monitor definition.
+func (o Object) Value() interface{} {
+	if o.Kind == KindDashboard {
+		return o.Dashboard
+	}
+	return o.Monitor
+}
+
+var funcs = template.FuncMap{
+	"split":   strings.Split,
+	"join":    strings.Join,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"replace": strings.Replace,
+	"trim":    strings.TrimSpace,
+	// json writes a value as JSON, e.g. {{json .message}} for a quoted and
+	// escaped string.
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseVars parses comma separated key=value pairs, e.g. service=checkout,env=prod.
+func ParseVars(s string) (map[string]interface{}, error) {
+	vars := map[string]interface{}{}
+	if strings.TrimSpace(s) == "" {
+		return vars, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", pair)
+		}
+		vars[strings.TrimSpace(kv[0])] = kv[1]
+	}
+	return vars, nil
+}
+
+// Dir renders every file in the dashboards and monitors subdirectories of dir
+// with vars. Each file must render to a JSON object or a list of objects.
+func Dir(dir string, vars map[string]interface{}) ([]Object, error) {
+	objects := []Object{}
+	found := false
+	for _, kind := range []string{KindDashboard, KindMonitor} {
+		files, err := ioutil.ReadDir(filepath.Join(dir, kind))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found = true
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].Name() < files[j].Name()
+		})
+		for _, f := range files {
+			if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+				continue
+			}
+			path := filepath.Join(dir, kind, f.Name())
+			rendered, err := File(path, kind, vars)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", path, err.Error())
+			}
+			objects = append(objects, rendered...)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no %s or %s directory in %s", KindDashboard, KindMonitor, dir)
+	}
+	return objects, nil
+}
+
+// File renders a single template of the given kind.
+func File(path string, kind string, vars map[string]interface{}) ([]Object, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(funcs).Option("missingkey=error").Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	out := bytes.Buffer{}
+	if err := tmpl.Execute(&out, vars); err != nil {
+		return nil, err
+	}
+
+	var raws []json.RawMessage
+	trimmed := bytes.TrimSpace(out.Bytes())
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &raws); err != nil {
+			return nil, errors.New("rendered invalid JSON: " + err.Error())
+		}
+	} else {
+		raws = []json.RawMessage{trimmed}
+	}
+
+	name := strings.SplitN(filepath.Base(path), ".", 2)[0]
+	objects := []Object{}
+	for i, raw := range raws {
+		o := Object{Kind: kind, Name: name}
+		if len(raws) > 1 {
+			o.Name = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		switch kind {
+		case KindDashboard:
+			o.Dashboard, err = DecodeDashboard(raw)
+		case KindMonitor:
+			o.Monitor, err = DecodeMonitor(raw)
+		default:
+			err = errors.New("unknown kind " + kind)
+		}
+		if err != nil {
+			if len(raws) > 1 {
+				return nil, fmt.Errorf("object %d: %s", i+1, err.Error())
+			}
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+// DecodeMonitor decodes and checks a monitor, such as one written by export.
+// Metric monitor queries must parse and validate.
+func DecodeMonitor(b []byte) (*datadog.MonitorDefinition, error) {
+	// decoding into the full type as well checks the types of fields it knows
+	if err := json.Unmarshal(b, &datadog.Monitor{}); err != nil {
+		return nil, errors.New("invalid monitor: " + err.Error())
+	}
+	def := new(datadog.MonitorDefinition)
+	if err := json.Unmarshal(b, def); err != nil {
+		return nil, errors.New("invalid monitor: " + err.Error())
+	}
+	switch {
+	case def.Name == "":
+		return nil, errors.New("monitor name required")
+	case def.Type == "":
+		return nil, errors.New("monitor type required")
+	case def.Query == "":
+		return nil, errors.New("monitor query required")
+	}
+	if def.Type == "metric alert" || def.Type == "query alert" {
+		if err := checkQuery(def.Query); err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+// DecodeDashboard decodes and checks a dashboard, such as one written by
+// export. Graph request metric queries must parse and validate. Requests
+// without one, such as log and APM queries, aren't checked.
+func DecodeDashboard(b []byte) (*datadog.DashboardDefinition, error) {
+	dash := datadog.Dashboard{}
+	if err := json.Unmarshal(b, &dash); err != nil {
+		return nil, errors.New("invalid dashboard: " + err.Error())
+	}
+	def := new(datadog.DashboardDefinition)
+	if err := json.Unmarshal(b, def); err != nil {
+		return nil, errors.New("invalid dashboard: " + err.Error())
+	}
+	if def.Title == "" {
+		return nil, errors.New("dashboard title required")
+	}
+	for i, graph := range dash.Graphs {
+		for _, r := range graph.Definition.Requests {
+			if r.Q == "" {
+				continue
+			}
+			if err := checkQuery(r.Q); err != nil {
+				return nil, fmt.Errorf("graph %d (%s): %s", i+1, graph.Title, err.Error())
+			}
+		}
+	}
+	return def, nil
+}
+
+func checkQuery(q string) error {
+	expr, err := query.Parse(q)
+	if err != nil {
+		return fmt.Errorf("invalid query %q: %s", q, err.Error())
+	}
+	if errs := query.Validate(expr); len(errs) > 0 {
+		return fmt.Errorf("invalid query %q: %s", q, errs[0].Error())
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package render
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplates(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "render")
+	require.NoError(t, err)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0777))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0666))
+	}
+	return dir
+}
+
+func TestParseVars(t *testing.T) {
+	vars, err := ParseVars("service=checkout, env=prod,filter=a=b")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"service": "checkout", "env": "prod", "filter": "a=b"}, vars)
+
+	vars, err = ParseVars("")
+	require.NoError(t, err)
+	require.Empty(t, vars)
+
+	_, err = ParseVars("service")
+	require.EqualError(t, err, `expected key=value, got "service"`)
+}
+
+func TestDir(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"dashboards/service.json.tmpl": `{
+			"title": "{{.service}} ({{.env}})",
+			"graphs": [
+				{"title": "Requests", "definition": {"viz": "timeseries", "requests": [{"q": "sum:{{.service}}.requests{env:{{.env}}}.as_count()"}]}}
+			]
+		}`,
+		"monitors/endpoints.json.tmpl": `[
+			{{- range $i, $endpoint := split .endpoints "|" }}{{ if $i }},{{ end }}
+			{
+				"name": {{ json (printf "%s %s errors" $.service $endpoint) }},
+				"type": "metric alert",
+				"query": "avg(last_5m):sum:{{$.service}}.errors{env:{{$.env}},endpoint:{{$endpoint}}} > 10",
+				"message": "@slack-{{$.service}}",
+				"tags": ["service:{{$.service}}"],
+				"options": {"thresholds": {"critical": 10}}
+			}
+			{{- end }}
+		]`,
+		"monitors/.hidden": `not a template`,
+	})
+	defer os.RemoveAll(dir)
+
+	objects, err := Dir(dir, map[string]interface{}{"service": "checkout", "env": "prod", "endpoints": "/cart|/pay"})
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+
+	require.Equal(t, KindDashboard, objects[0].Kind)
+	require.Equal(t, "service", objects[0].Name)
+	require.Equal(t, "checkout (prod)", objects[0].Dashboard.Title)
+
+	require.Equal(t, KindMonitor, objects[1].Kind)
+	require.Equal(t, "endpoints-1", objects[1].Name)
+	require.Equal(t, "checkout /cart errors", objects[1].Monitor.Name)
+	require.Equal(t, "avg(last_5m):sum:checkout.errors{env:prod,endpoint:/pay} > 10", objects[2].Monitor.Query)
+	require.Equal(t, []string{"service:checkout"}, objects[2].Monitor.Tags)
+	require.Equal(t, map[string]interface{}{"thresholds": map[string]interface{}{"critical": 10.0}}, objects[2].Monitor.Options)
+}
+
+func TestDirErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			"missing var",
+			map[string]string{"monitors/m.json": `{"name": "{{.nope}}"}`},
+			`map has no entry for key "nope"`,
+		},
+		{
+			"missing field",
+			map[string]string{"monitors/m.json": `{"name": "CPU", "type": "metric alert"}`},
+			"monitor query required",
+		},
+		{
+			"wrong type",
+			map[string]string{"monitors/m.json": `{"name": "CPU", "type": "metric alert", "query": "x", "tags": "env:prod"}`},
+			"invalid monitor: json: cannot unmarshal string",
+		},
+		{
+			"invalid query",
+			map[string]string{"monitors/m.json": `{"name": "CPU", "type": "metric alert", "query": "avg(last_5m):foo:system.cpu.user{*} > 90"}`},
+			`unknown aggregator "foo"`,
+		},
+		{
+			"invalid graph query",
+			map[string]string{"dashboards/d.json": `{"title": "Hosts", "graphs": [{"title": "CPU", "definition": {"requests": [{"q": "avg:system.cpu.user{*"}]}}]}`},
+			"graph 1 (CPU): invalid query",
+		},
+		{
+			"list item",
+			map[string]string{"monitors/m.json": `[{"name": "A", "type": "service check", "query": "x"}, {"name": "B"}]`},
+			"object 2: monitor type required",
+		},
+		{
+			"no directories",
+			map[string]string{"other/m.json": `{}`},
+			"no dashboards or monitors directory",
+		},
+	}
+
+	for _, test := range tests {
+		dir := writeTemplates(t, test.files)
+		_, err := Dir(dir, map[string]interface{}{})
+		os.RemoveAll(dir)
+		require.Error(t, err, test.name)
+		require.Contains(t, err.Error(), test.expected, test.name)
+	}
+}
+
+func TestDecodeDashboard(t *testing.T) {
+	// as written by export, with a log query request and several series in one request
+	dash, err := DecodeDashboard([]byte(`{
+		"id": 150947,
+		"title": "Checkout",
+		"graphs": [
+			{"title": "Requests", "definition": {"viz": "timeseries", "requests": [
+				{"q": "sum:app.requests{service:checkout}.as_count(), sum:app.errors{service:checkout}.as_count()", "type": "line"},
+				{"log_query": {"index": "main", "search": {"query": "service:checkout status:error"}, "compute": {"aggregation": "count"}}, "type": "bars"}
+			]}},
+			{"title": "Latency", "definition": {"viz": "timeseries", "requests": [
+				{"q": "avg:app.latency{service:checkout}.rollup(avg, 60) by {host}"},
+				{"apm_query": {"index": "trace-search", "search": {"query": "service:checkout"}, "compute": {"aggregation": "count"}}}
+			]}}
+		]
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, "Checkout", dash.Title)
+	require.Len(t, dash.Graphs, 2)
+	require.Len(t, dash.Requests(), 4)
+
+	_, err = DecodeDashboard([]byte(`{"title": "Bad", "graphs": [{"title": "Requests", "definition": {"requests": [{"q": "sum:a{*}, foo:b{*}"}]}}]}`))
+	require.EqualError(t, err, `graph 1 (Requests): invalid query "sum:a{*}, foo:b{*}": unknown aggregator "foo" for metric b`)
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+var reportCommand = cli.Command{
+	Name:  "report",
+	Usage: "reports on dashboards, screenboards and monitors",
+	Subcommands: []cli.Command{
+		{
+			Name:  "stale",
+			Usage: "list dashboards, screenboards and monitors nobody has touched recently, as cleanup candidates",
+			Description: "Items are stale if they haven't been modified within --older-than. Monitors are\n" +
+				"   also stale if every group has had no data since before then. Nothing is deleted unless\n" +
+				"   both --delete and --confirm are given, and each item is backed up first.",
+			Action: staleReport,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "older-than",
+					Value: "180d",
+					Usage: "Age to consider stale, in days, e.g. 180d, or a duration, e.g. 720h",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "csv",
+					Usage: "Format, either csv or md (markdown)",
+				},
+				cli.BoolFlag{
+					Name:  "delete",
+					Usage: "Delete the stale items, requires --confirm",
+				},
+				cli.BoolFlag{
+					Name:  "confirm",
+					Usage: "Confirm deleting the stale items",
+				},
+				cli.StringFlag{
+					Name:  "backup-dir",
+					Value: "stale-backup",
+					Usage: "Directory to back items up to before deleting them",
+				},
+			},
+		},
+		{
+			Name:   "owners",
+			Usage:  "count dashboards, screenboards and monitors by creator and team: tag",
+			Action: ownersReport,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "csv",
+					Usage: "Format, either csv or md (markdown)",
+				},
+				cli.BoolFlag{
+					Name:  "items",
+					Usage: "List each item rather than counts",
+				},
+				cli.BoolFlag{
+					Name:  "disabled-only",
+					Usage: "Only list items whose creator is disabled",
+				},
+			},
+		},
+	},
+}
+
+// parseAge parses a number of days such as 180d, or a duration such as 720h.
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days < 0 {
+			return 0, errors.New("invalid number of days: " + s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+type staleItem struct {
+	Type     string
+	ID       string
+	Title    string
+	Modified time.Time
+	State    string
+	Creator  string
+	Reason   string
+	// backup returns the item's full definition, as accepted when creating
+	// it, to save before deleting it.
+	backup func() (interface{}, error)
+	delete func() error
+}
+
+func staleReport(c *cli.Context) error {
+	age, err := parseAge(c.String("older-than"))
+	if err != nil {
+		return errors.New("invalid --older-than: " + err.Error())
+	}
+	if c.Bool("delete") && !c.Bool("confirm") {
+		return errors.New("--delete requires --confirm")
+	}
+	cutoff := time.Now().Add(-1 * age)
+
+	api := getAPI()
+	items, err := getStaleItems(api, cutoff)
+	if err != nil {
+		return err
+	}
+	users, err := getUsersByHandle(api)
+	if err != nil {
+		return err
+	}
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Type", "ID", "Title", "Modified", "State", "Creator", "Creator status", "Reason"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, item := range items {
+		if err := w.Write([]string{
+			item.Type,
+			item.ID,
+			item.Title,
+			item.Modified.Local().Format("2006-01-02"),
+			item.State,
+			item.Creator,
+			creatorStatus(users, item.Creator),
+			item.Reason,
+		}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+
+	if !c.Bool("delete") {
+		return nil
+	}
+	backupDir := c.String("backup-dir")
+	for i, item := range items {
+		log.Printf("Deleting %s %s (%d of %d)...", item.Type, item.ID, i+1, len(items))
+		v, err := item.backup()
+		if err != nil {
+			return fmt.Errorf("failed to get %s %s to back up: %s", item.Type, item.ID, err.Error())
+		}
+		dir := path.Join(backupDir, item.Type+"s")
+		createDirectories(dir)
+		if err := writeJSONFile(path.Join(dir, item.ID+".json"), v); err != nil {
+			return fmt.Errorf("failed to back up %s %s: %s", item.Type, item.ID, err.Error())
+		}
+		if err := item.delete(); err != nil {
+			return fmt.Errorf("failed to delete %s %s: %s", item.Type, item.ID, err.Error())
+		}
+	}
+	log.Printf("Deleted %d items, backed up to %s", len(items), backupDir)
+	return nil
+}
+
+// getStaleItems returns the dashboards, screenboards and monitors that are
+// stale as of cutoff, least recently modified first.
+func getStaleItems(api *datadog.API, cutoff time.Time) ([]staleItem, error) {
+	notModified := "not modified since " + cutoff.Local().Format("2006-01-02")
+	items := []staleItem{}
+
+	dashes, err := api.GetDashboards()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dashes {
+		if !d.Modified.Before(cutoff) {
+			continue
+		}
+		id := d.ID
+		items = append(items, staleItem{
+			Type:     "dashboard",
+			ID:       id,
+			Title:    d.Title,
+			Modified: d.Modified,
+			Creator:  creatorOf(d.CreatedBy),
+			Reason:   notModified,
+			backup:   func() (interface{}, error) { return api.GetDashboardDefinition(id) },
+			delete:   func() error { return api.DeleteDashboard(id) },
+		})
+	}
+
+	screenboards, err := api.GetScreenboards()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range screenboards {
+		if !s.Modified.Before(cutoff) {
+			continue
+		}
+		id := s.ID
+		items = append(items, staleItem{
+			Type:     "screenboard",
+			ID:       strconv.Itoa(id),
+			Title:    s.Title,
+			Modified: s.Modified,
+			Creator:  creatorOf(s.CreatedBy),
+			Reason:   notModified,
+			backup:   func() (interface{}, error) { return api.GetScreenboardDefinition(id) },
+			delete:   func() error { return api.DeleteScreenboard(id) },
+		})
+	}
+
+	monitors, err := api.GetMonitors()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range monitors {
+		reason := ""
+		if m.Modified.Before(cutoff) {
+			reason = notModified
+		} else if m.OverallState == "No Data" {
+			since, err := noDataSince(api, m.ID)
+			if err != nil {
+				return nil, err
+			}
+			if !since.IsZero() && since.Before(cutoff) {
+				reason = "no data since " + since.Local().Format("2006-01-02")
+			}
+		}
+		if reason == "" {
+			continue
+		}
+		monitor := m
+		items = append(items, staleItem{
+			Type:     "monitor",
+			ID:       strconv.Itoa(m.ID),
+			Title:    m.Name,
+			Modified: m.Modified,
+			State:    m.OverallState,
+			Creator:  creatorEmail(m.Creator),
+			Reason:   reason,
+			backup:   func() (interface{}, error) { return api.GetMonitorDefinition(monitor.ID) },
+			delete:   func() error { return api.DeleteMonitor(monitor.ID) },
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Modified.Before(items[j].Modified)
+	})
+	return items, nil
+}
+
+func creatorOf(creator *datadog.Creator) string {
+	if creator == nil {
+		return ""
+	}
+	return creatorEmail(*creator)
+}
+
+// noDataSince returns when the last of a monitor's groups stopped reporting
+// data, or the zero time if any group is reporting.
+func noDataSince(api *datadog.API, id int) (time.Time, error) {
+	monitor, err := api.GetMonitor(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if monitor.State == nil || len(monitor.State.Groups) == 0 {
+		return time.Time{}, nil
+	}
+	var latest int64
+	for _, group := range monitor.State.Groups {
+		if group.Status != "No Data" {
+			return time.Time{}, nil
+		}
+		if group.LastNoDataTS > latest {
+			latest = group.LastNoDataTS
+		}
+	}
+	if latest == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(latest, 0), nil
+}
+
+// getUsersByHandle returns all users by their lowercased handle and email.
+func getUsersByHandle(api *datadog.API) (map[string]datadog.User, error) {
+	users, err := api.GetUsers()
+	if err != nil {
+		return nil, err
+	}
+	byHandle := map[string]datadog.User{}
+	for _, u := range users {
+		byHandle[strings.ToLower(u.Handle)] = u
+		if u.Email != "" {
+			byHandle[strings.ToLower(u.Email)] = u
+		}
+	}
+	return byHandle, nil
+}
+
+// creatorStatus returns whether the user with the given handle or email is
+// active, disabled or no longer exists.
+func creatorStatus(users map[string]datadog.User, handle string) string {
+	if handle == "" {
+		return ""
+	}
+	u, ok := users[strings.ToLower(handle)]
+	switch {
+	case !ok:
+		return "unknown"
+	case u.Disabled:
+		return "disabled"
+	}
+	return "active"
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+type ownedItem struct {
+	Type    string
+	ID      string
+	Title   string
+	Creator string
+	Team    string
+	// CreatorDisabled is set when the item itself says its creator is disabled.
+	CreatorDisabled bool
+}
+
+func ownersReport(c *cli.Context) error {
+	api := getAPI()
+	items, err := getOwnedItems(api)
+	if err != nil {
+		return err
+	}
+	users, err := getUsersByHandle(api)
+	if err != nil {
+		return err
+	}
+	status := func(item ownedItem) string {
+		if item.CreatorDisabled {
+			return "disabled"
+		}
+		return creatorStatus(users, item.Creator)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Creator != items[j].Creator {
+			return items[i].Creator < items[j].Creator
+		}
+		return items[i].Team < items[j].Team
+	})
+
+	w := newColumnWriter(c.String("format"))
+	if c.Bool("items") {
+		if err := w.Write([]string{"Creator", "Creator status", "Team", "Type", "ID", "Title"}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+		for _, item := range items {
+			s := status(item)
+			if c.Bool("disabled-only") && s != "disabled" {
+				continue
+			}
+			if err := w.Write([]string{item.Creator, s, item.Team, item.Type, item.ID, item.Title}); err != nil {
+				return errors.New("failed to write output: " + err.Error())
+			}
+		}
+		w.Flush()
+		return nil
+	}
+
+	// count items per creator and team, which are already sorted together
+	if err := w.Write([]string{"Creator", "Creator status", "Team", "Dashboards", "Screenboards", "Monitors"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for i := 0; i < len(items); {
+		first := items[i]
+		s := status(first)
+		counts := map[string]int{}
+		for ; i < len(items) && items[i].Creator == first.Creator && items[i].Team == first.Team; i++ {
+			counts[items[i].Type]++
+			if status(items[i]) == "disabled" {
+				s = "disabled"
+			}
+		}
+		if c.Bool("disabled-only") && s != "disabled" {
+			continue
+		}
+		if err := w.Write([]string{
+			first.Creator,
+			s,
+			first.Team,
+			strconv.Itoa(counts["dashboard"]),
+			strconv.Itoa(counts["screenboard"]),
+			strconv.Itoa(counts["monitor"]),
+		}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+// getOwnedItems returns every dashboard, screenboard and monitor with its
+// creator's email and team tags.
+func getOwnedItems(api *datadog.API) ([]ownedItem, error) {
+	items := []ownedItem{}
+
+	dashes, err := api.GetDashboards()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dashes {
+		item := ownedItem{Type: "dashboard", ID: d.ID, Title: d.Title}
+		if d.CreatedBy != nil {
+			item.Creator = creatorEmail(*d.CreatedBy)
+			item.CreatorDisabled = d.CreatedBy.Disabled
+		}
+		items = append(items, item)
+	}
+
+	screenboards, err := api.GetScreenboards()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range screenboards {
+		item := ownedItem{Type: "screenboard", ID: strconv.Itoa(s.ID), Title: s.Title}
+		if s.CreatedBy != nil {
+			item.Creator = creatorEmail(*s.CreatedBy)
+			item.CreatorDisabled = s.CreatedBy.Disabled
+		}
+		items = append(items, item)
+	}
+
+	monitors, err := api.GetMonitors()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range monitors {
+		items = append(items, ownedItem{
+			Type:    "monitor",
+			ID:      strconv.Itoa(m.ID),
+			Title:   m.Name,
+			Creator: creatorEmail(m.Creator),
+			Team:    teamTags(m.Tags),
+		})
+	}
+	return items, nil
+}
+
+// creatorEmail returns the lowercased email of an item's creator, or their
+// handle if the email isn't known.
+func creatorEmail(creator datadog.Creator) string {
+	if creator.Email != "" {
+		return strings.ToLower(creator.Email)
+	}
+	return strings.ToLower(creator.Handle)
+}
+
+// teamTags returns the values of any team: tags, sorted and comma separated.
+func teamTags(tags []string) string {
+	teams := []string{}
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "team:") {
+			teams = append(teams, strings.TrimPrefix(tag, "team:"))
+		}
+	}
+	sort.Strings(teams)
+	return strings.Join(teams, ",")
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+var sloCommand = cli.Command{
+	Name:  "slo",
+	Usage: "SLO commands",
+	Subcommands: []cli.Command{
+		{
+			Name:   "report",
+			Usage:  "report each SLO's target, SLI, remaining error budget and burn rate",
+			Action: sloReport,
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "window, w",
+					Value: "30d",
+					Usage: "Window to report on, one of 7d, 30d or 90d",
+				},
+				cli.StringSliceFlag{
+					Name:  "tags, t",
+					Usage: "Only report on SLOs with this tag (repeatable)",
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Value: "md",
+					Usage: "Format, either csv or md (markdown)",
+				},
+			},
+		},
+	},
+}
+
+var sloWindows = map[string]time.Duration{
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+}
+
+func sloReport(c *cli.Context) error {
+	timeframe := c.String("window")
+	window, ok := sloWindows[timeframe]
+	if !ok {
+		return errors.New("--window must be one of 7d, 30d or 90d")
+	}
+
+	api := getAPI()
+	slos, err := api.GetSLOs()
+	if err != nil {
+		return err
+	}
+	sort.Slice(slos, func(i, j int) bool {
+		return strings.ToLower(slos[i].Name) < strings.ToLower(slos[j].Name)
+	})
+
+	to := time.Now()
+	from := to.Add(-1 * window)
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"SLO", "Type", "Target", "SLI", "Error budget remaining", "Burn rate"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, slo := range slos {
+		if !hasTags(slo.Tags, c.StringSlice("tags")) {
+			continue
+		}
+		threshold, ok := slo.Threshold(timeframe)
+		if !ok {
+			log.Printf("Skipping SLO %q, it has no %s target", slo.Name, timeframe)
+			continue
+		}
+
+		history, err := api.GetSLOHistory(slo.ID, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to get history of SLO %q: %s", slo.Name, err.Error())
+		}
+		row := []string{slo.Name, slo.Type, strconv.FormatFloat(threshold.Target, 'f', -1, 64) + "%", "no data", "", ""}
+		if history.Overall.SLIValue != nil {
+			sli := *history.Overall.SLIValue
+			remaining, burnRate := datadog.ErrorBudget(threshold.Target, sli)
+			row[3] = strconv.FormatFloat(sli, 'f', 3, 64) + "%"
+			row[4] = strconv.FormatFloat(remaining, 'f', 1, 64) + "%"
+			row[5] = strconv.FormatFloat(burnRate, 'f', 2, 64)
+		}
+		if err := w.Write(row); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+// hasTags returns whether tags contains every one of want.
+func hasTags(tags []string, want []string) bool {
+	for _, wanted := range want {
+		found := false
+		for _, tag := range tags {
+			if tag == wanted {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// exportSLOs writes every SLO to a file in dir.
+func exportSLOs(dd *datadog.API, dir string) error {
+	createDirectories(dir)
+	slos, err := dd.GetSLOs()
+	if err != nil {
+		return errors.New("failed to get SLOs: " + err.Error())
+	}
+	for _, slo := range slos {
+		if err := writeJSONFile(path.Join(dir, slo.ID+".json"), slo); err != nil {
+			return err
+		}
+	}
+	log.Printf("Exported %d SLOs", len(slos))
+	return nil
+}
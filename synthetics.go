@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"time"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+var syntheticsCommand = cli.Command{
+	Name:  "synthetics",
+	Usage: "synthetics commands",
+	Subcommands: []cli.Command{
+		{
+			Name:   "trigger",
+			Usage:  "start CI runs of synthetics tests, optionally waiting for them to pass",
+			Action: triggerSynthetics,
+			Flags: []cli.Flag{
+				cli.StringSliceFlag{
+					Name:  "public-id",
+					Usage: "Public ID of the test to run, e.g. abc-def-ghi (repeatable)",
+				},
+				cli.BoolFlag{
+					Name:  "wait",
+					Usage: "Wait for the results, exiting non-zero if any test fails",
+				},
+				cli.DurationFlag{
+					Name:  "timeout",
+					Value: 30 * time.Minute,
+					Usage: "How long to wait for results before failing",
+				},
+				cli.DurationFlag{
+					Name:  "interval",
+					Value: 10 * time.Second,
+					Usage: "How often to poll for results",
+				},
+			},
+		},
+	},
+}
+
+func triggerSynthetics(c *cli.Context) error {
+	publicIDs := c.StringSlice("public-id")
+	if len(publicIDs) == 0 {
+		return errors.New("at least one --public-id required")
+	}
+
+	api := getAPI()
+	batchID, err := api.TriggerSyntheticsTests(publicIDs)
+	if err != nil {
+		return err
+	}
+	if !c.Bool("wait") {
+		fmt.Println(batchID)
+		return nil
+	}
+	log.Printf("Triggered batch %s, waiting for results...", batchID)
+
+	deadline := time.Now().Add(c.Duration("timeout"))
+	for {
+		batch, err := api.GetSyntheticsBatch(batchID)
+		if err != nil {
+			return err
+		}
+		// the status can be empty until the runs have been scheduled
+		if batch.Status != datadog.SyntheticsStatusInProgress && batch.Status != "" {
+			return reportSyntheticsBatch(batch)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for batch %s", c.Duration("timeout"), batchID)
+		}
+		time.Sleep(c.Duration("interval"))
+	}
+}
+
+// reportSyntheticsBatch prints the result of each test run in a finished
+// batch. Only a passed batch where every run passed succeeds, so a deploy
+// doesn't go ahead without its tests having run.
+func reportSyntheticsBatch(batch *datadog.SyntheticsBatch) error {
+	notPassed := 0
+	for _, r := range batch.Results {
+		location := r.Location
+		if r.Device != "" {
+			location += " " + r.Device
+		}
+		fmt.Printf("%-7s %s (%s) %s %.0fms\n", r.Status, r.TestName, r.TestPublicID, location, r.Duration)
+		if r.Status != datadog.SyntheticsStatusPassed {
+			notPassed++
+		}
+	}
+	switch {
+	case len(batch.Results) == 0:
+		return fmt.Errorf("synthetics batch %s with no test runs", batch.Status)
+	case notPassed > 0:
+		return fmt.Errorf("%d of %d synthetics test runs didn't pass", notPassed, len(batch.Results))
+	case batch.Status != datadog.SyntheticsStatusPassed:
+		return fmt.Errorf("synthetics batch %s", batch.Status)
+	}
+	return nil
+}
+
+// exportSynthetics writes synthetics tests and global variables to the tests
+// and variables subdirectories of dir.
+func exportSynthetics(dd *datadog.API, dir string) error {
+	testsDir := path.Join(dir, "tests")
+	variablesDir := path.Join(dir, "variables")
+	createDirectories(testsDir, variablesDir)
+
+	tests, err := dd.GetSyntheticsTests()
+	if err != nil {
+		return errors.New("failed to get synthetics tests: " + err.Error())
+	}
+	for i, info := range tests {
+		log.Printf("Getting synthetics test %d of %d...", i+1, len(tests))
+		test, err := dd.GetSyntheticsTest(info.PublicID)
+		if err != nil {
+			return fmt.Errorf("failed to get synthetics test %s: %s", info.PublicID, err.Error())
+		}
+		if err := writeJSONFile(path.Join(testsDir, info.PublicID+".json"), test); err != nil {
+			return err
+		}
+	}
+	log.Printf("Exported %d synthetics tests", len(tests))
+
+	variables, err := dd.GetSyntheticsGlobalVariables()
+	if err != nil {
+		return errors.New("failed to get synthetics global variables: " + err.Error())
+	}
+	for _, variable := range variables {
+		if err := writeJSONFile(path.Join(variablesDir, variable.ID+".json"), variable); err != nil {
+			return err
+		}
+	}
+	log.Printf("Exported %d synthetics global variables", len(variables))
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSyntheticsBatch(t *testing.T) {
+	passed := datadog.SyntheticsBatchResult{Status: datadog.SyntheticsStatusPassed, TestName: "Checkout", TestPublicID: "abc-def-ghi"}
+	failed := datadog.SyntheticsBatchResult{Status: datadog.SyntheticsStatusFailed, TestName: "Login", TestPublicID: "jkl-mno-pqr"}
+	skipped := datadog.SyntheticsBatchResult{Status: datadog.SyntheticsStatusSkipped, TestName: "Search", TestPublicID: "stu-vwx-yza"}
+	tests := []struct {
+		name     string
+		batch    datadog.SyntheticsBatch
+		expected string
+	}{
+		{"passed", datadog.SyntheticsBatch{Status: "passed", Results: []datadog.SyntheticsBatchResult{passed}}, ""},
+		{"failed run", datadog.SyntheticsBatch{Status: "failed", Results: []datadog.SyntheticsBatchResult{passed, failed}}, "1 of 2 synthetics test runs didn't pass"},
+		{"skipped run", datadog.SyntheticsBatch{Status: "passed", Results: []datadog.SyntheticsBatchResult{skipped}}, "1 of 1 synthetics test runs didn't pass"},
+		{"no runs", datadog.SyntheticsBatch{Status: "passed"}, "synthetics batch passed with no test runs"},
+		{"unknown status", datadog.SyntheticsBatch{Status: "canceled", Results: []datadog.SyntheticsBatchResult{passed}}, "synthetics batch canceled"},
+	}
+	for _, test := range tests {
+		err := reportSyntheticsBatch(&test.batch)
+		if test.expected == "" {
+			require.NoError(t, err, test.name)
+		} else {
+			require.EqualError(t, err, test.expected, test.name)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/porty/ddcli/datadog"
+	"github.com/urfave/cli"
+)
+
+var accessFormatFlag = cli.StringFlag{
+	Name:  "format, f",
+	Value: "csv",
+	Usage: "Format, either csv or md (markdown)",
+}
+
+var usersCommand = cli.Command{
+	Name:  "users",
+	Usage: "user commands",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list users with their roles, status and last login",
+			Action: listUsers,
+			Flags: []cli.Flag{
+				accessFormatFlag,
+				cli.BoolFlag{
+					Name:  "active-only",
+					Usage: "Don't list disabled users",
+				},
+			},
+		},
+	},
+}
+
+var rolesCommand = cli.Command{
+	Name:  "roles",
+	Usage: "role commands",
+	Subcommands: []cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list roles with their permissions",
+			Action: listRoles,
+			Flags: []cli.Flag{
+				accessFormatFlag,
+			},
+		},
+	},
+}
+
+func listUsers(c *cli.Context) error {
+	api := getAPI()
+	users, err := api.GetUsers()
+	if err != nil {
+		return err
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return strings.ToLower(users[i].Handle) < strings.ToLower(users[j].Handle)
+	})
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Handle", "Name", "Roles", "Status", "Last login"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, u := range users {
+		if c.Bool("active-only") && u.Disabled {
+			continue
+		}
+		lastLogin := ""
+		if u.LastLoginTime != nil {
+			lastLogin = u.LastLoginTime.Local().Format("2006-01-02 15:04")
+		}
+		if err := w.Write([]string{u.Handle, u.Name, strings.Join(u.Roles, ", "), u.Status, lastLogin}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+func listRoles(c *cli.Context) error {
+	api := getAPI()
+	roles, err := api.GetRoles()
+	if err != nil {
+		return err
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return strings.ToLower(roles[i].Name) < strings.ToLower(roles[j].Name)
+	})
+
+	w := newColumnWriter(c.String("format"))
+	if err := w.Write([]string{"Role", "Users", "Permissions"}); err != nil {
+		return errors.New("failed to write output: " + err.Error())
+	}
+	for _, r := range roles {
+		permissions := append([]string{}, r.Permissions...)
+		sort.Strings(permissions)
+		if err := w.Write([]string{r.Name, strconv.Itoa(r.UserCount), strings.Join(permissions, " ")}); err != nil {
+			return errors.New("failed to write output: " + err.Error())
+		}
+	}
+	w.Flush()
+	return nil
+}
+
+// exportAccess writes users, roles and teams to users.json, roles.json and
+// teams.json in dir, for access reviews.
+func exportAccess(dd *datadog.API, dir string) error {
+	createDirectories(dir)
+
+	users, err := dd.GetUsers()
+	if err != nil {
+		return errors.New("failed to get users: " + err.Error())
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Handle < users[j].Handle
+	})
+	if err := writeJSONFile(path.Join(dir, "users.json"), users); err != nil {
+		return err
+	}
+	log.Printf("Exported %d users", len(users))
+
+	roles, err := dd.GetRoles()
+	if err != nil {
+		return errors.New("failed to get roles: " + err.Error())
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	if err := writeJSONFile(path.Join(dir, "roles.json"), roles); err != nil {
+		return err
+	}
+	log.Printf("Exported %d roles", len(roles))
+
+	teams, err := dd.GetTeams()
+	if err != nil {
+		return errors.New("failed to get teams: " + err.Error())
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Handle < teams[j].Handle
+	})
+	if err := writeJSONFile(path.Join(dir, "teams.json"), teams); err != nil {
+		return err
+	}
+	log.Printf("Exported %d teams", len(teams))
+	return nil
+}